@@ -0,0 +1,225 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	defaultAccessTokenDays = 30
+	maxAccessTokenDays     = 365
+	maxAccessTokensPerUser = 50
+)
+
+func CreateAccessToken(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		uid, err := bson.ObjectIDFromHex(userId.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+			return
+		}
+
+		var req struct {
+			Name          string   `json:"name"`
+			Scopes        []string `json:"scopes"`
+			ExpiresInDays int      `json:"expires_in_days"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		req.Name = strings.TrimSpace(req.Name)
+		if req.Name == "" || len(req.Name) > 64 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Name must be between 1 and 64 characters"})
+			return
+		}
+
+		if len(req.Scopes) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":            "At least one scope is required",
+				"available_scopes": utils.AccessTokenScopes,
+			})
+			return
+		}
+
+		scopes := []string{}
+		for _, scope := range req.Scopes {
+			if !utils.ValidAccessTokenScope(scope) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":            "Unknown scope: " + scope,
+					"available_scopes": utils.AccessTokenScopes,
+				})
+				return
+			}
+			if !slices.Contains(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+
+		days := req.ExpiresInDays
+		if days == 0 {
+			days = defaultAccessTokenDays
+		}
+		if days < 0 || days > maxAccessTokenDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expires_in_days must be between 1 and 365"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		tokenCol := database.OpenCollection("access_tokens", client)
+
+		count, err := tokenCol.CountDocuments(ctx, bson.M{
+			"user_id":    uid,
+			"revoked_at": bson.M{"$exists": false},
+			"expires_at": bson.M{"$gt": time.Now()},
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+			return
+		}
+		if count >= maxAccessTokensPerUser {
+			c.JSON(http.StatusConflict, gin.H{"error": "Too many active tokens, revoke one first"})
+			return
+		}
+
+		raw, err := utils.GenerateAccessToken()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+			return
+		}
+
+		now := time.Now()
+		pat := models.PersonalAccessToken{
+			ID:        bson.NewObjectID(),
+			UserID:    uid,
+			Name:      req.Name,
+			TokenHash: utils.HashToken(raw),
+			Prefix:    raw[:len(utils.AccessTokenPrefix)+6],
+			Scopes:    scopes,
+			CreatedAt: now,
+			ExpiresAt: now.AddDate(0, 0, days),
+		}
+
+		if _, err := tokenCol.InsertOne(ctx, pat); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"token":        raw,
+			"access_token": pat,
+			"message":      "Copy this token now, it will not be shown again",
+		})
+	}
+}
+
+func ListAccessTokens(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		uid, err := bson.ObjectIDFromHex(userId.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		cursor, err := database.OpenCollection("access_tokens", client).Find(
+			ctx,
+			bson.M{
+				"user_id":    uid,
+				"revoked_at": bson.M{"$exists": false},
+			},
+			options.Find().SetSort(bson.M{"created_at": -1}),
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tokens"})
+			return
+		}
+		defer cursor.Close(ctx)
+
+		tokens := []models.PersonalAccessToken{}
+		if err := cursor.All(ctx, &tokens); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse tokens"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"tokens":           tokens,
+			"available_scopes": utils.AccessTokenScopes,
+		})
+	}
+}
+
+func RevokeAccessToken(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		uid, err := bson.ObjectIDFromHex(userId.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+			return
+		}
+
+		tokenId, err := bson.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token id"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		res, err := database.OpenCollection("access_tokens", client).UpdateOne(
+			ctx,
+			bson.M{
+				"_id":        tokenId,
+				"user_id":    uid,
+				"revoked_at": bson.M{"$exists": false},
+			},
+			bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke token"})
+			return
+		}
+
+		if res.MatchedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Token not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Token revoked"})
+	}
+}
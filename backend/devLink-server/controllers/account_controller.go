@@ -0,0 +1,331 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const emailChangeOTPTTL = 10 * time.Minute
+
+func RequestEmailChange(client *mongo.Client, mailer utils.Mailer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		uid, err := bson.ObjectIDFromHex(userId.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+			return
+		}
+
+		var req struct {
+			NewEmail string `json:"new_email" validate:"required,email"`
+			Password string `json:"password" validate:"required"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data"})
+			return
+		}
+
+		req.NewEmail = strings.TrimSpace(req.NewEmail)
+
+		if err := validator.New().Struct(req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		userCollection := database.OpenCollection("users", client)
+
+		var user models.User
+		if err := userCollection.FindOne(ctx, bson.M{"_id": uid}).Decode(&user); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Password is incorrect"})
+			return
+		}
+
+		if req.NewEmail == user.Email {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "New email is the same as the current one"})
+			return
+		}
+
+		count, err := userCollection.CountDocuments(ctx, bson.M{"email": req.NewEmail})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check existing user"})
+			return
+		}
+
+		if count > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email already in use"})
+			return
+		}
+
+		otp := GenerateOTP()
+		otpHash, err := HashPassword(otp)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate OTP"})
+			return
+		}
+
+		if _, err := userCollection.UpdateOne(ctx, bson.M{"_id": uid}, bson.M{
+			"$set": bson.M{
+				"pending_email":    req.NewEmail,
+				"email_otp_hash":   otpHash,
+				"email_otp_expiry": time.Now().Add(emailChangeOTPTTL),
+				"updated_at":       time.Now(),
+			},
+			"$unset": bson.M{"email_otp_attempts": ""},
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start email change"})
+			return
+		}
+
+		if err := utils.SendOTPEmail(ctx, mailer, req.NewEmail, user.Locale, otp); err != nil {
+			log.Println("EMAIL CHANGE OTP FAILED:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to send OTP email. Please try again.",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "OTP sent to the new email address",
+		})
+	}
+}
+
+func VerifyEmailChange(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		uid, err := bson.ObjectIDFromHex(userId.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+			return
+		}
+
+		var req struct {
+			OTP string `json:"otp"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		userCollection := database.OpenCollection("users", client)
+
+		var user models.User
+		if err := userCollection.FindOne(ctx, bson.M{"_id": uid}).Decode(&user); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		if user.PendingEmail == "" || user.EmailOTPHash == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No email change pending"})
+			return
+		}
+
+		if time.Now().After(user.EmailOTPExpiry) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "OTP expired"})
+			return
+		}
+
+		user, attempts, err := reserveOTPAttempt(ctx, userCollection, uid, "email_otp_hash", "email_otp_attempts")
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Too many incorrect attempts. Please request a new OTP.",
+				"code":  "OTP_REQUIRED",
+			})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Email change failed"})
+			return
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(user.EmailOTPHash), []byte(req.OTP)); err != nil {
+			if burnOTP(ctx, userCollection, uid, attempts, "email_otp_hash", "email_otp_expiry", "email_otp_attempts") {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Too many incorrect attempts. Please request a new OTP.",
+					"code":  "OTP_REQUIRED",
+				})
+				return
+			}
+
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid OTP"})
+			return
+		}
+
+		count, err := userCollection.CountDocuments(ctx, bson.M{"email": user.PendingEmail})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check existing user"})
+			return
+		}
+
+		if count > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email already in use"})
+			return
+		}
+
+		// The unique email index settles a race with a signup or another
+		// change to the same address.
+		if _, err := userCollection.UpdateOne(ctx, bson.M{"_id": uid}, bson.M{
+			"$set": bson.M{
+				"email":      user.PendingEmail,
+				"updated_at": time.Now(),
+			},
+			"$unset": bson.M{
+				"pending_email":      "",
+				"email_otp_hash":     "",
+				"email_otp_expiry":   "",
+				"email_otp_attempts": "",
+			},
+		}); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Email already in use"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Email change failed"})
+			return
+		}
+
+		token, err := utils.GenerateToken(uid.Hex(), user.PendingEmail, user.Role, c.GetString("session_id"))
+		if err == nil {
+			setAuthCookie(c, token)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Email updated",
+			"email":   user.PendingEmail,
+		})
+	}
+}
+
+// DeleteAccount schedules the account for deletion. Logging in again within
+// utils.AccountDeletionGrace restores it; after that a background job purges
+// the user's data.
+func DeleteAccount(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		uid, err := bson.ObjectIDFromHex(userId.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+			return
+		}
+
+		var req struct {
+			Password     string `json:"password" validate:"required"`
+			Code         string `json:"code"`
+			RecoveryCode string `json:"recovery_code"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data"})
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Password is required"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		userCollection := database.OpenCollection("users", client)
+
+		var user models.User
+		if err := userCollection.FindOne(ctx, bson.M{"_id": uid}).Decode(&user); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		if user.Password == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Set a password with the password reset flow before deleting your account",
+				"code":  "PASSWORD_NOT_SET",
+			})
+			return
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Password is incorrect"})
+			return
+		}
+
+		if user.TOTPEnabled {
+			ok, err := verifySecondFactor(ctx, userCollection, user, req.Code, req.RecoveryCode, time.Now())
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Verification failed"})
+				return
+			}
+			if !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code", "code": "MFA_REQUIRED"})
+				return
+			}
+		}
+
+		now := time.Now()
+		if _, err := userCollection.UpdateOne(ctx, bson.M{"_id": uid}, bson.M{
+			"$set": bson.M{
+				"deletion_requested_at": now,
+				"updated_at":            now,
+			},
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Account deletion failed"})
+			return
+		}
+
+		if _, err := utils.RevokeUserSessions(ctx, client, uid); err != nil {
+			log.Println("SESSION REVOCATION FAILED:", err)
+		}
+		DisconnectUser(uid.Hex(), "account deleted")
+
+		clearAuthCookie(c)
+		clearRefreshCookie(c)
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":     "Your account will be deleted. Log in again before the purge date to restore it.",
+			"purge_after": now.Add(utils.AccountDeletionGrace),
+		})
+	}
+}
@@ -0,0 +1,578 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	defaultAdminPageSize = 20
+	maxAdminPageSize     = 100
+)
+
+type AdminUserResponse struct {
+	ID          bson.ObjectID              `json:"id"`
+	Name        string                     `json:"name"`
+	Email       string                     `json:"email"`
+	Role        string                     `json:"role"`
+	IsVerified  bool                       `json:"is_verified"`
+	TOTPEnabled bool                       `json:"totp_enabled"`
+	Restriction *models.AccountRestriction `json:"restriction,omitempty"`
+	CreatedAt   time.Time                  `json:"created_at"`
+	LastSeen    *time.Time                 `json:"last_seen,omitempty"`
+}
+
+func newAdminUserResponse(user models.User) AdminUserResponse {
+	return AdminUserResponse{
+		ID:          user.Id,
+		Name:        user.UserName,
+		Email:       user.Email,
+		Role:        user.Role,
+		IsVerified:  user.IsVerified,
+		TOTPEnabled: user.TOTPEnabled,
+		Restriction: utils.ActiveRestriction(user.Restriction, time.Now()),
+		CreatedAt:   user.CreatedAt,
+		LastSeen:    user.LastSeen,
+	}
+}
+
+type adminActor struct {
+	ID   bson.ObjectID
+	Role string
+}
+
+func currentAdmin(c *gin.Context) (adminActor, bool) {
+	userId, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return adminActor{}, false
+	}
+
+	uid, err := bson.ObjectIDFromHex(userId.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+		return adminActor{}, false
+	}
+
+	return adminActor{ID: uid, Role: c.GetString("role")}, true
+}
+
+func adminPage(c *gin.Context) (int64, int64) {
+	page, _ := strconv.ParseInt(c.DefaultQuery("page", "1"), 10, 64)
+	limit, _ := strconv.ParseInt(c.DefaultQuery("limit", strconv.Itoa(defaultAdminPageSize)), 10, 64)
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > maxAdminPageSize {
+		limit = defaultAdminPageSize
+	}
+	return page, limit
+}
+
+// audit records an admin action. The action has already happened, so a
+// failed write is logged rather than reported to the caller.
+func audit(ctx context.Context, c *gin.Context, client *mongo.Client, actor adminActor, action, targetType string, targetId bson.ObjectID, reason string, details bson.M) {
+	if err := utils.WriteAuditLog(ctx, client, models.AuditLog{
+		ActorID:    actor.ID,
+		ActorRole:  actor.Role,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetId,
+		Reason:     reason,
+		Details:    details,
+		IP:         c.ClientIP(),
+	}); err != nil {
+		log.Println("AUDIT LOG WRITE FAILED:", action, targetId.Hex(), err)
+	}
+}
+
+// loadAdminTarget fetches the user named in the route and checks that the
+// actor outranks them, so moderators cannot act on moderators or admins and
+// nobody can act on themselves.
+func loadAdminTarget(ctx context.Context, c *gin.Context, userCollection *mongo.Collection, actor adminActor) (models.User, bool) {
+	targetId, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+		return models.User{}, false
+	}
+
+	if targetId == actor.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot perform this action on your own account"})
+		return models.User{}, false
+	}
+
+	var target models.User
+	if err := userCollection.FindOne(ctx, bson.M{"_id": targetId}).Decode(&target); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return models.User{}, false
+	}
+
+	if utils.RoleRank(target.Role) >= utils.RoleRank(actor.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot act on a user with an equal or higher role"})
+		return models.User{}, false
+	}
+
+	return target, true
+}
+
+func AdminListUsers(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, limit := adminPage(c)
+
+		filter := bson.M{}
+
+		if q := strings.TrimSpace(c.Query("q")); q != "" {
+			pattern := regexp.QuoteMeta(q)
+			filter["$or"] = []bson.M{
+				{"name": bson.M{"$regex": pattern, "$options": "i"}},
+				{"email": bson.M{"$regex": pattern, "$options": "i"}},
+			}
+		}
+
+		if role := c.Query("role"); role != "" {
+			filter["role"] = role
+		}
+
+		switch status := c.Query("status"); status {
+		case utils.StatusSuspended, utils.StatusBanned:
+			filter["restriction.status"] = status
+			filter["$and"] = []bson.M{{"$or": []bson.M{
+				{"restriction.until": bson.M{"$exists": false}},
+				{"restriction.until": bson.M{"$gt": time.Now()}},
+			}}}
+		case "active":
+			filter["$and"] = []bson.M{{"$or": []bson.M{
+				{"restriction": bson.M{"$exists": false}},
+				{"restriction.until": bson.M{"$lte": time.Now()}},
+			}}}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		userCollection := database.OpenCollection("users", client)
+
+		total, err := userCollection.CountDocuments(ctx, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+			return
+		}
+
+		cursor, err := userCollection.Find(
+			ctx,
+			filter,
+			options.Find().
+				SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+				SetSkip((page-1)*limit).
+				SetLimit(limit),
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+			return
+		}
+		defer cursor.Close(ctx)
+
+		var users []models.User
+		if err := cursor.All(ctx, &users); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse users"})
+			return
+		}
+
+		response := make([]AdminUserResponse, 0, len(users))
+		for _, user := range users {
+			response = append(response, newAdminUserResponse(user))
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"users": response,
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		})
+	}
+}
+
+// AdminRestrictUser suspends or bans the user named in the route. The body
+// takes a reason and an optional RFC 3339 "until"; without it the
+// restriction lasts until lifted.
+func AdminRestrictUser(client *mongo.Client, status string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor, ok := currentAdmin(c)
+		if !ok {
+			return
+		}
+
+		var req struct {
+			Reason string     `json:"reason"`
+			Until  *time.Time `json:"until"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		req.Reason = strings.TrimSpace(req.Reason)
+		if req.Reason == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required"})
+			return
+		}
+
+		now := time.Now()
+
+		if req.Until != nil && !req.Until.After(now) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "until must be in the future"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		userCollection := database.OpenCollection("users", client)
+
+		target, ok := loadAdminTarget(ctx, c, userCollection, actor)
+		if !ok {
+			return
+		}
+
+		previous := utils.ActiveRestriction(target.Restriction, now)
+		if previous != nil && previous.Status == status {
+			c.JSON(http.StatusConflict, gin.H{"error": "User is already " + status})
+			return
+		}
+
+		restriction := models.AccountRestriction{
+			Status:    status,
+			Reason:    req.Reason,
+			Until:     req.Until,
+			CreatedAt: now,
+			CreatedBy: actor.ID,
+		}
+
+		if _, err := userCollection.UpdateOne(ctx, bson.M{"_id": target.Id}, bson.M{
+			"$set": bson.M{
+				"restriction": restriction,
+				"updated_at":  now,
+			},
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+			return
+		}
+
+		revoked, err := utils.RevokeUserSessions(ctx, client, target.Id)
+		if err != nil {
+			log.Println("RESTRICT SESSION REVOKE FAILED:", err)
+		}
+
+		sockets := DisconnectUser(target.Id.Hex(), "Account "+status)
+
+		action := utils.AuditUserSuspend
+		if status == utils.StatusBanned {
+			action = utils.AuditUserBan
+		}
+
+		details := bson.M{
+			"sessions_revoked": revoked,
+			"sockets_closed":   sockets,
+		}
+		if req.Until != nil {
+			details["until"] = *req.Until
+		}
+		if previous != nil {
+			details["previous_status"] = previous.Status
+		}
+
+		audit(ctx, c, client, actor, action, "user", target.Id, req.Reason, details)
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":     "User " + status,
+			"restriction": restriction,
+		})
+	}
+}
+
+// AdminLiftRestriction removes a suspension or ban of the given status.
+func AdminLiftRestriction(client *mongo.Client, status string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor, ok := currentAdmin(c)
+		if !ok {
+			return
+		}
+
+		var req struct {
+			Reason string `json:"reason"`
+		}
+		c.ShouldBindJSON(&req)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		userCollection := database.OpenCollection("users", client)
+
+		target, ok := loadAdminTarget(ctx, c, userCollection, actor)
+		if !ok {
+			return
+		}
+
+		current := utils.ActiveRestriction(target.Restriction, time.Now())
+		if current == nil || current.Status != status {
+			c.JSON(http.StatusConflict, gin.H{"error": "User is not " + status})
+			return
+		}
+
+		if _, err := userCollection.UpdateOne(ctx, bson.M{"_id": target.Id}, bson.M{
+			"$set":   bson.M{"updated_at": time.Now()},
+			"$unset": bson.M{"restriction": ""},
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+			return
+		}
+
+		action := utils.AuditUserUnsuspend
+		if status == utils.StatusBanned {
+			action = utils.AuditUserUnban
+		}
+
+		audit(ctx, c, client, actor, action, "user", target.Id, strings.TrimSpace(req.Reason), bson.M{
+			"previous_reason": current.Reason,
+		})
+
+		c.JSON(http.StatusOK, gin.H{"message": "Restriction lifted"})
+	}
+}
+
+func AdminVerifyUser(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor, ok := currentAdmin(c)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		userCollection := database.OpenCollection("users", client)
+
+		target, ok := loadAdminTarget(ctx, c, userCollection, actor)
+		if !ok {
+			return
+		}
+
+		if target.IsVerified {
+			c.JSON(http.StatusConflict, gin.H{"error": "User is already verified"})
+			return
+		}
+
+		if _, err := userCollection.UpdateOne(ctx, bson.M{"_id": target.Id}, bson.M{
+			"$set": bson.M{
+				"is_verified": true,
+				"updated_at":  time.Now(),
+			},
+			"$unset": bson.M{
+				"otp_hash":     "",
+				"otp_expiry":   "",
+				"otp_attempts": "",
+			},
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify user"})
+			return
+		}
+
+		audit(ctx, c, client, actor, utils.AuditUserVerify, "user", target.Id, "", nil)
+
+		c.JSON(http.StatusOK, gin.H{"message": "User verified"})
+	}
+}
+
+func AdminSetUserRole(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor, ok := currentAdmin(c)
+		if !ok {
+			return
+		}
+
+		var req struct {
+			Role string `json:"role"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		if !utils.ValidRole(req.Role) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown role"})
+			return
+		}
+
+		if utils.RoleRank(req.Role) >= utils.RoleRank(actor.Role) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can only grant roles below your own"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		userCollection := database.OpenCollection("users", client)
+
+		target, ok := loadAdminTarget(ctx, c, userCollection, actor)
+		if !ok {
+			return
+		}
+
+		if target.Role == req.Role {
+			c.JSON(http.StatusOK, gin.H{"message": "Role unchanged"})
+			return
+		}
+
+		if _, err := userCollection.UpdateOne(ctx, bson.M{"_id": target.Id}, bson.M{
+			"$set": bson.M{
+				"role":       req.Role,
+				"updated_at": time.Now(),
+			},
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+			return
+		}
+
+		// Access tokens carry the role, so end the user's sessions to make
+		// the change take effect immediately.
+		if _, err := utils.RevokeUserSessions(ctx, client, target.Id); err != nil {
+			log.Println("ROLE CHANGE SESSION REVOKE FAILED:", err)
+		}
+
+		audit(ctx, c, client, actor, utils.AuditUserSetRole, "user", target.Id, "", bson.M{
+			"from": target.Role,
+			"to":   req.Role,
+		})
+
+		c.JSON(http.StatusOK, gin.H{"message": "Role updated", "role": req.Role})
+	}
+}
+
+func AdminUnpublishPost(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor, ok := currentAdmin(c)
+		if !ok {
+			return
+		}
+
+		postId, err := bson.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post id"})
+			return
+		}
+
+		var req struct {
+			Reason string `json:"reason"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		req.Reason = strings.TrimSpace(req.Reason)
+		if req.Reason == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var post models.Post
+		err = database.OpenCollection("posts", client).FindOneAndUpdate(
+			ctx,
+			bson.M{"_id": postId, "published": true},
+			bson.M{"$set": bson.M{
+				"status":     utils.PostArchived,
+				"published":  false,
+				"updated_at": time.Now(),
+			}},
+		).Decode(&post)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Published post not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unpublish post"})
+			return
+		}
+
+		audit(ctx, c, client, actor, utils.AuditPostUnpublish, "post", post.ID, req.Reason, bson.M{
+			"author_id": post.AuthorID,
+			"slug":      post.Slug,
+			"title":     post.Title,
+		})
+
+		c.JSON(http.StatusOK, gin.H{"message": "Post unpublished"})
+	}
+}
+
+func AdminAuditLog(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, limit := adminPage(c)
+
+		filter := bson.M{}
+
+		if action := c.Query("action"); action != "" {
+			filter["action"] = action
+		}
+
+		for _, field := range []string{"actor_id", "target_id"} {
+			if v := c.Query(field); v != "" {
+				id, err := bson.ObjectIDFromHex(v)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + field})
+					return
+				}
+				filter[field] = id
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		cursor, err := database.OpenCollection("audit_logs", client).Find(
+			ctx,
+			filter,
+			options.Find().
+				SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+				SetSkip((page-1)*limit).
+				SetLimit(limit),
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit log"})
+			return
+		}
+		defer cursor.Close(ctx)
+
+		entries := []models.AuditLog{}
+		if err := cursor.All(ctx, &entries); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse audit log"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"entries": entries,
+			"page":    page,
+			"limit":   limit,
+		})
+	}
+}
@@ -3,13 +3,17 @@ package controllers
 import (
 	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"math/big"
 	"net/http"
 	"net/url"
-	
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/ayushmehta03/devLink-backend/database"
 	"github.com/ayushmehta03/devLink-backend/models"
@@ -18,6 +22,7 @@ import (
 	"github.com/go-playground/validator/v10"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -29,7 +34,7 @@ func setAuthCookie(c *gin.Context, token string) {
 	http.SetCookie(c.Writer, &http.Cookie{
 		Name:     "access_token",
 		Value:    token,
-		MaxAge:   60 * 60 * 24,
+		MaxAge:   int(utils.AccessTokenTTL.Seconds()),
 		Path:     "/",
 		Domain:   cookieDomain(),
 		HttpOnly: true,
@@ -51,6 +56,196 @@ func clearAuthCookie(c *gin.Context) {
 	})
 }
 
+func setRefreshCookie(c *gin.Context, token string) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     "refresh_token",
+		Value:    token,
+		MaxAge:   int(utils.RefreshTokenTTL.Seconds()),
+		Path:     "/api/auth",
+		Domain:   cookieDomain(),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+}
+
+func clearRefreshCookie(c *gin.Context) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     "refresh_token",
+		Value:    "",
+		MaxAge:   -1,
+		Path:     "/api/auth",
+		Domain:   cookieDomain(),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+}
+
+func startSession(ctx context.Context, c *gin.Context, client *mongo.Client, user models.User) error {
+	// Logging in during the deletion grace period cancels the deletion.
+	// Once it is over the account is left to the purge.
+	if user.DeletionRequestedAt != nil {
+		res, err := database.OpenCollection("users", client).UpdateOne(ctx, bson.M{
+			"_id": user.Id,
+			"$or": []bson.M{
+				{"deletion_requested_at": bson.M{"$exists": false}},
+				{"deletion_requested_at": bson.M{"$gt": time.Now().Add(-utils.AccountDeletionGrace)}},
+			},
+		}, bson.M{
+			"$set":   bson.M{"updated_at": time.Now()},
+			"$unset": bson.M{"deletion_requested_at": ""},
+		})
+		if err != nil {
+			return err
+		}
+		if res.MatchedCount == 0 {
+			return utils.ErrAccountDeleted
+		}
+	}
+
+	session, refreshToken, err := utils.CreateSession(ctx, client, user.Id, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		return err
+	}
+
+	token, err := utils.GenerateToken(user.Id.Hex(), user.Email, user.Role, session.ID.Hex())
+	if err != nil {
+		return err
+	}
+
+	setAuthCookie(c, token)
+	setRefreshCookie(c, refreshToken)
+	return nil
+}
+
+// respondSessionError answers for a login whose session could not be
+// started.
+func respondSessionError(c *gin.Context, err error) {
+	if errors.Is(err, utils.ErrAccountDeleted) {
+		c.JSON(http.StatusGone, gin.H{"error": "This account has been deleted", "code": "ACCOUNT_DELETED"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start session"})
+}
+
+// rejectRestricted answers 403 when a moderator has suspended or banned the
+// user and reports whether it did.
+func rejectRestricted(c *gin.Context, user models.User) bool {
+	restriction := utils.ActiveRestriction(user.Restriction, time.Now())
+	if restriction == nil {
+		return false
+	}
+
+	c.JSON(http.StatusForbidden, utils.RestrictionResponse(restriction))
+	return true
+}
+
+const (
+	maxOTPAttempts = 5
+	otpResendDelay = 60 * time.Second
+)
+
+type attemptKey struct {
+	limiter utils.AttemptLimiter
+	key     string
+}
+
+func tooManyAttempts(c *gin.Context, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "Too many attempts. Please try again later.",
+		"code":        "TOO_MANY_ATTEMPTS",
+		"retry_after": seconds,
+	})
+}
+
+// allowAttempt writes a 429 and returns false when any of the keys is
+// locked out. Limiter errors fail open so an outage cannot lock everyone out.
+func allowAttempt(ctx context.Context, c *gin.Context, keys ...attemptKey) bool {
+	var wait time.Duration
+	for _, k := range keys {
+		d, err := k.limiter.Check(ctx, k.key)
+		if err != nil {
+			log.Println("LIMITER CHECK FAILED:", err)
+			continue
+		}
+		if d > wait {
+			wait = d
+		}
+	}
+
+	if wait > 0 {
+		tooManyAttempts(c, wait)
+		return false
+	}
+	return true
+}
+
+func recordFailedAttempt(ctx context.Context, keys ...attemptKey) {
+	for _, k := range keys {
+		if _, err := k.limiter.Fail(ctx, k.key); err != nil {
+			log.Println("LIMITER UPDATE FAILED:", err)
+		}
+	}
+}
+
+func resetAttempts(ctx context.Context, keys ...attemptKey) {
+	for _, k := range keys {
+		if err := k.limiter.Reset(ctx, k.key); err != nil {
+			log.Println("LIMITER RESET FAILED:", err)
+		}
+	}
+}
+
+// reserveOTPAttempt counts an attempt at the code in hashField before the
+// code is compared, so guesses sent in parallel cannot get past
+// maxOTPAttempts. It returns the user as of the reservation together with
+// the attempts used, or mongo.ErrNoDocuments when there is no code or no
+// attempt left.
+func reserveOTPAttempt(ctx context.Context, userCollection *mongo.Collection, userId bson.ObjectID, hashField, attemptsField string) (models.User, int64, error) {
+	var user models.User
+
+	var updated bson.Raw
+	err := userCollection.FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"_id":         userId,
+			hashField:     bson.M{"$exists": true},
+			attemptsField: bson.M{"$not": bson.M{"$gte": maxOTPAttempts}},
+		},
+		bson.M{"$inc": bson.M{attemptsField: 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		return user, 0, err
+	}
+
+	if err := bson.Unmarshal(updated, &user); err != nil {
+		return user, 0, err
+	}
+	attempts, _ := updated.Lookup(attemptsField).AsInt64OK()
+	return user, attempts, nil
+}
+
+// burnOTP drops the code once a wrong guess has used the last attempt. It
+// reports whether the code is burnt.
+func burnOTP(ctx context.Context, userCollection *mongo.Collection, userId bson.ObjectID, attempts int64, hashField, expiryField, attemptsField string) bool {
+	if attempts < maxOTPAttempts {
+		return false
+	}
+
+	userCollection.UpdateOne(ctx, bson.M{"_id": userId}, bson.M{
+		"$unset": bson.M{
+			hashField:     "",
+			expiryField:   "",
+			attemptsField: "",
+		},
+	})
+	return true
+}
+
 func GenerateOTP() string {
 	max := big.NewInt(1000000)
 	n, err := rand.Int(rand.Reader, max)
@@ -68,18 +263,27 @@ func HashPassword(password string) (string, error) {
 	return string(bytes), nil
 }
 
-func RegisterUser(client *mongo.Client) gin.HandlerFunc {
+func RegisterUser(client *mongo.Client, mailer utils.Mailer) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
-		var user models.User
+		// Only what a signup form asks for is bound; profile fields go through
+		// UpdateProfile and its validation.
+		var req struct {
+			UserName string `json:"name" validate:"required,min=5,max=22"`
+			Email    string `json:"email" validate:"required,email"`
+			Password string `json:"password" validate:"required,min=6"`
+			Handle   string `json:"handle"`
+			Bio      string `json:"bio"`
+			Locale   string `json:"locale"`
+		}
 
-		if err := c.ShouldBindJSON(&user); err != nil {
+		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data"})
 			return
 		}
 
 		validate := validator.New()
-		if err := validate.Struct(user); err != nil {
+		if err := validate.Struct(req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error":   "Validation failed",
 				"details": err.Error(),
@@ -87,7 +291,20 @@ func RegisterUser(client *mongo.Client) gin.HandlerFunc {
 			return
 		}
 
-		hashedPassword, err := HashPassword(user.Password)
+		if utf8.RuneCountInString(req.Bio) > utils.MaxBioLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Bio must be at most 160 characters"})
+			return
+		}
+
+		user := models.User{
+			UserName: req.UserName,
+			Email:    req.Email,
+			Handle:   req.Handle,
+			Bio:      req.Bio,
+			Locale:   req.Locale,
+		}
+
+		hashedPassword, err := HashPassword(req.Password)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 			return
@@ -109,6 +326,20 @@ func RegisterUser(client *mongo.Client) gin.HandlerFunc {
 			return
 		}
 
+		if user.Handle != "" {
+			user.Handle = utils.NormalizeHandle(user.Handle)
+			if err := utils.CheckHandleAvailable(ctx, client, user.Handle, bson.ObjectID{}); err != nil {
+				respondHandleError(c, err)
+				return
+			}
+		} else {
+			user.Handle, err = utils.GenerateHandle(ctx, client, user.UserName)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register user"})
+				return
+			}
+		}
+
 		seed := user.UserName
 		if seed == "" {
 			seed = user.Email
@@ -126,18 +357,30 @@ func RegisterUser(client *mongo.Client) gin.HandlerFunc {
 		user.Password = hashedPassword
 		user.IsVerified = false
 		user.Role = "user"
+		user.Locale = utils.ResolveLocale(user.Locale, c.GetHeader("Accept-Language"))
 		user.OTPHash = otpHash
 		user.ProfileImage = avatarURL
 		user.OTPExpiry = time.Now().Add(10 * time.Minute)
+		user.OTPSentAt = time.Now()
 		user.CreatedAt = time.Now()
 		user.UpdatedAt = time.Now()
 
 		if _, err := userCollection.InsertOne(ctx, user); err != nil {
+			// The checks above race with concurrent signups; the unique
+			// indexes settle which one wins.
+			switch utils.DuplicateKeyField(err) {
+			case "email":
+				c.JSON(http.StatusConflict, gin.H{"error": "User already exists"})
+				return
+			case "handle":
+				respondHandleError(c, utils.ErrHandleTaken)
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register user"})
 			return
 		}
 
-		if err := utils.SendOTPEmail(user.Email, otp); err != nil {
+		if err := utils.SendOTPEmail(ctx, mailer, user.Email, user.Locale, otp); err != nil {
 			log.Println("OTP EMAIL FAILED:", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to send OTP email. Please try again.",
@@ -152,6 +395,8 @@ func RegisterUser(client *mongo.Client) gin.HandlerFunc {
 }
 
 func VerifyOtp(client *mongo.Client) gin.HandlerFunc {
+	ipLimiter := utils.NewAttemptLimiter(client, "otp_ip", utils.IPLockoutPolicy)
+
 	return func(c *gin.Context) {
 
 		var req struct {
@@ -167,10 +412,16 @@ func VerifyOtp(client *mongo.Client) gin.HandlerFunc {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
+		ipKey := attemptKey{ipLimiter, c.ClientIP()}
+		if !allowAttempt(ctx, c, ipKey) {
+			return
+		}
+
 		userCollection := database.OpenCollection("users", client)
 
 		var user models.User
 		if err := userCollection.FindOne(ctx, bson.M{"email": req.Email}).Decode(&user); err != nil {
+			recordFailedAttempt(ctx, ipKey)
 			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 			return
 		}
@@ -180,24 +431,57 @@ func VerifyOtp(client *mongo.Client) gin.HandlerFunc {
 			return
 		}
 
+		if user.OTPHash == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "No active OTP. Please request a new one.",
+				"code":  "OTP_REQUIRED",
+			})
+			return
+		}
+
 		if time.Now().After(user.OTPExpiry) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "OTP expired"})
 			return
 		}
 
+		user, attempts, err := reserveOTPAttempt(ctx, userCollection, user.Id, "otp_hash", "otp_attempts")
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Too many incorrect attempts. Please request a new OTP.",
+				"code":  "OTP_REQUIRED",
+			})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Verification failed"})
+			return
+		}
+
 		if err := bcrypt.CompareHashAndPassword([]byte(user.OTPHash), []byte(req.OTP)); err != nil {
+			recordFailedAttempt(ctx, ipKey)
+
+			if burnOTP(ctx, userCollection, user.Id, attempts, "otp_hash", "otp_expiry", "otp_attempts") {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Too many incorrect attempts. Please request a new OTP.",
+					"code":  "OTP_REQUIRED",
+				})
+				return
+			}
+
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid OTP"})
 			return
 		}
 
-		_, err := userCollection.UpdateOne(ctx, bson.M{"email": req.Email}, bson.M{
+		_, err = userCollection.UpdateOne(ctx, bson.M{"_id": user.Id, "otp_hash": user.OTPHash}, bson.M{
 			"$set": bson.M{
 				"is_verified": true,
 				"updated_at":  time.Now(),
 			},
 			"$unset": bson.M{
-				"otp_hash":   "",
-				"otp_expiry": "",
+				"otp_hash":     "",
+				"otp_expiry":   "",
+				"otp_attempts": "",
+				"otp_sent_at":  "",
 			},
 		})
 		if err != nil {
@@ -205,8 +489,14 @@ func VerifyOtp(client *mongo.Client) gin.HandlerFunc {
 			return
 		}
 
-		token, _ := utils.GenerateToken(user.Id.Hex(), user.Email, user.Role)
-		setAuthCookie(c, token)
+		if rejectRestricted(c, user) {
+			return
+		}
+
+		if err := startSession(ctx, c, client, user); err != nil {
+			respondSessionError(c, err)
+			return
+		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"message": "Account verified and logged in",
@@ -215,6 +505,9 @@ func VerifyOtp(client *mongo.Client) gin.HandlerFunc {
 }
 
 func LoginUser(client *mongo.Client) gin.HandlerFunc {
+	accountLimiter := utils.NewAttemptLimiter(client, "login_account", utils.AccountLockoutPolicy)
+	ipLimiter := utils.NewAttemptLimiter(client, "login_ip", utils.IPLockoutPolicy)
+
 	return func(c *gin.Context) {
 
 		var loginReq models.UserLogin
@@ -227,10 +520,18 @@ func LoginUser(client *mongo.Client) gin.HandlerFunc {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
+		accountKey := attemptKey{accountLimiter, strings.ToLower(strings.TrimSpace(loginReq.Email))}
+		ipKey := attemptKey{ipLimiter, c.ClientIP()}
+
+		if !allowAttempt(ctx, c, accountKey, ipKey) {
+			return
+		}
+
 		userCollection := database.OpenCollection("users", client)
 
 		var user models.User
 		if err := userCollection.FindOne(ctx, bson.M{"email": loginReq.Email}).Decode(&user); err != nil {
+			recordFailedAttempt(ctx, accountKey, ipKey)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "No account found with this email"})
 			return
 		}
@@ -245,12 +546,36 @@ func LoginUser(client *mongo.Client) gin.HandlerFunc {
 		}
 
 		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginReq.Password)); err != nil {
+			recordFailedAttempt(ctx, accountKey, ipKey)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
 			return
 		}
 
-		token, _ := utils.GenerateToken(user.Id.Hex(), user.Email, user.Role)
-		setAuthCookie(c, token)
+		resetAttempts(ctx, accountKey)
+
+		if rejectRestricted(c, user) {
+			return
+		}
+
+		if user.TOTPEnabled {
+			mfaToken, err := utils.GeneratePurposeToken(user.Id.Hex(), "mfa", mfaTokenTTL)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{
+				"message":      "Two-factor authentication required",
+				"mfa_required": true,
+				"mfa_token":    mfaToken,
+			})
+			return
+		}
+
+		if err := startSession(ctx, c, client, user); err != nil {
+			respondSessionError(c, err)
+			return
+		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"message": "Login successful",
@@ -258,9 +583,92 @@ func LoginUser(client *mongo.Client) gin.HandlerFunc {
 	}
 }
 
-func LogoutUser() gin.HandlerFunc {
+func RefreshToken(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		refreshToken, err := c.Cookie("refresh_token")
+		if err != nil || refreshToken == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token missing"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		session, newRefreshToken, err := utils.RotateSession(ctx, client, refreshToken, c.ClientIP())
+		if err != nil {
+			clearAuthCookie(c)
+			clearRefreshCookie(c)
+
+			if errors.Is(err, utils.ErrRefreshTokenReuse) {
+				log.Println("REFRESH TOKEN REUSE: session revoked")
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": "Refresh token reuse detected, please log in again",
+					"code":  "REFRESH_TOKEN_REUSED",
+				})
+				return
+			}
+
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+			return
+		}
+
+		userCollection := database.OpenCollection("users", client)
+
+		var user models.User
+		if err := userCollection.FindOne(ctx, bson.M{"_id": session.UserID}).Decode(&user); err != nil {
+			utils.RevokeSession(ctx, client, session.ID)
+			clearAuthCookie(c)
+			clearRefreshCookie(c)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
+			return
+		}
+
+		if utils.ActiveRestriction(user.Restriction, time.Now()) != nil {
+			utils.RevokeSession(ctx, client, session.ID)
+			clearAuthCookie(c)
+			clearRefreshCookie(c)
+			rejectRestricted(c, user)
+			return
+		}
+
+		token, err := utils.GenerateToken(user.Id.Hex(), user.Email, user.Role, session.ID.Hex())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue token"})
+			return
+		}
+
+		setAuthCookie(c, token)
+		setRefreshCookie(c, newRefreshToken)
+
+		c.Header("Cache-Control", "no-store")
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Token refreshed",
+		})
+	}
+}
+
+func LogoutUser(client *mongo.Client) gin.HandlerFunc {
 	return func(c *gin.Context) {
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if refreshToken, err := c.Cookie("refresh_token"); err == nil && refreshToken != "" {
+			utils.RevokeSessionByRefreshToken(ctx, client, refreshToken)
+		}
+
+		if tokenStr, err := c.Cookie("access_token"); err == nil {
+			if claims, err := utils.VerifyToken(tokenStr); err == nil {
+				if sid, err := bson.ObjectIDFromHex(claims.SessionID); err == nil {
+					utils.RevokeSession(ctx, client, sid)
+				}
+			}
+		}
+
 		clearAuthCookie(c)
+		clearRefreshCookie(c)
 
 		c.Header("Cache-Control", "no-store")
 
@@ -290,6 +698,11 @@ func GetMe(client *mongo.Client) gin.HandlerFunc {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
+		if !utils.IsSessionActive(ctx, client, claims.SessionID) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "session revoked"})
+			return
+		}
+
 		userCollection := database.OpenCollection("users", client)
 
 		var user models.User
@@ -301,15 +714,19 @@ func GetMe(client *mongo.Client) gin.HandlerFunc {
 		c.JSON(http.StatusOK, gin.H{
 			"id":            user.Id,
 			"username":      user.UserName,
+			"handle":        user.Handle,
 			"email":         user.Email,
 			"profile_image": user.ProfileImage,
 			"role":          user.Role,
+			"permissions":   utils.PermissionsFor(user.Role),
+			"totp_enabled":  user.TOTPEnabled,
+			"locale":        user.Locale,
 			"created_at":    user.CreatedAt,
 		})
 	}
 }
 
-func ResendOtp(client *mongo.Client) gin.HandlerFunc {
+func ResendOtp(client *mongo.Client, mailer utils.Mailer) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		var req struct {
@@ -337,6 +754,11 @@ func ResendOtp(client *mongo.Client) gin.HandlerFunc {
 			return
 		}
 
+		if wait := time.Until(user.OTPSentAt.Add(otpResendDelay)); wait > 0 {
+			tooManyAttempts(c, wait)
+			return
+		}
+
 		newOtp := GenerateOTP()
 		hashedOtp, err := HashPassword(newOtp)
 		if err != nil {
@@ -347,8 +769,12 @@ func ResendOtp(client *mongo.Client) gin.HandlerFunc {
 		update := bson.M{
 			"$set": bson.M{
 				"otp_hash":   hashedOtp,
-				"otp_expiry": time.Now().Add(10 * time.Minute),
-				"updated_at": time.Now(),
+				"otp_expiry":  time.Now().Add(10 * time.Minute),
+				"otp_sent_at": time.Now(),
+				"updated_at":  time.Now(),
+			},
+			"$unset": bson.M{
+				"otp_attempts": "",
 			},
 		}
 
@@ -357,7 +783,7 @@ func ResendOtp(client *mongo.Client) gin.HandlerFunc {
 			return
 		}
 
-		if err := utils.SendOTPEmail(user.Email, newOtp); err != nil {
+		if err := utils.SendOTPEmail(ctx, mailer, user.Email, user.Locale, newOtp); err != nil {
 			log.Println("RESEND OTP EMAIL FAILED:", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to send OTP email. Please try again.",
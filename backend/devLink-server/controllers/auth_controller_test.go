@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var otpPattern = regexp.MustCompile(`\b\d{6}\b`)
+
+// sentOTP returns the code in the last message mailed to to.
+func sentOTP(t *testing.T, mailer *utils.MemoryMailer, to string) string {
+	t.Helper()
+
+	msg, ok := mailer.Last()
+	if !ok {
+		t.Fatal("no email sent")
+	}
+	if msg.To != to {
+		t.Fatalf("email sent to %s, want %s", msg.To, to)
+	}
+
+	otp := otpPattern.FindString(msg.Text)
+	if otp == "" {
+		t.Fatalf("no code in email: %q", msg.Text)
+	}
+	return otp
+}
+
+func registerUser(t *testing.T, handler gin.HandlerFunc, email string) {
+	t.Helper()
+
+	rec := serveJSON(t, handler, "", gin.H{"name": "new developer", "email": email, "password": "secret123"})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("register: status = %d, want 201: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestRegisterUserMailsWorkingOTP(t *testing.T) {
+	client := testClient(t)
+	mailer := &utils.MemoryMailer{}
+
+	registerUser(t, RegisterUser(client, mailer), "new.dev@example.com")
+	otp := sentOTP(t, mailer, "new.dev@example.com")
+
+	rec := serveJSON(t, VerifyOtp(client), "", gin.H{"email": "new.dev@example.com", "otp": otp})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("verify: status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if len(rec.Result().Cookies()) == 0 {
+		t.Error("no session cookies set")
+	}
+
+	rec = serveJSON(t, RegisterUser(client, mailer), "", gin.H{"name": "other developer", "email": "new.dev@example.com", "password": "secret123"})
+	if rec.Code != http.StatusConflict {
+		t.Errorf("second signup: status = %d, want 409", rec.Code)
+	}
+}
+
+func TestRegisterUserLimitsBio(t *testing.T) {
+	rec := serveJSON(t, RegisterUser(nil, &utils.MemoryMailer{}), "", gin.H{
+		"name":     "new developer",
+		"email":    "new.dev@example.com",
+		"password": "secret123",
+		"bio":      strings.Repeat("é", utils.MaxBioLength+1),
+	})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestResendOtpReplacesCode(t *testing.T) {
+	client := testClient(t)
+	mailer := &utils.MemoryMailer{}
+
+	registerUser(t, RegisterUser(client, mailer), "new.dev@example.com")
+	first := sentOTP(t, mailer, "new.dev@example.com")
+
+	rec := serveJSON(t, ResendOtp(client, mailer), "", gin.H{"email": "new.dev@example.com"})
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("immediate resend: status = %d, want 429", rec.Code)
+	}
+
+	// Let the resend delay pass.
+	if _, err := database.OpenCollection("users", client).UpdateOne(context.Background(),
+		bson.M{"email": "new.dev@example.com"},
+		bson.M{"$set": bson.M{"otp_sent_at": time.Now().Add(-otpResendDelay)}},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	rec = serveJSON(t, ResendOtp(client, mailer), "", gin.H{"email": "new.dev@example.com"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("resend: status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if n := len(mailer.Sent()); n != 2 {
+		t.Fatalf("sent %d emails, want 2", n)
+	}
+	second := sentOTP(t, mailer, "new.dev@example.com")
+
+	if first != second {
+		rec = serveJSON(t, VerifyOtp(client), "", gin.H{"email": "new.dev@example.com", "otp": first})
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("replaced code: status = %d, want 400", rec.Code)
+		}
+	}
+
+	rec = serveJSON(t, VerifyOtp(client), "", gin.H{"email": "new.dev@example.com", "otp": second})
+	if rec.Code != http.StatusOK {
+		t.Errorf("resent code: status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+}
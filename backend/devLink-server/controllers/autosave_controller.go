@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MaxAutosaveBytes bounds an autosave request, which editors send often.
+const MaxAutosaveBytes = 1 << 20
+
+// findOwnPost loads the post named by :id if the user wrote it, writing
+// the error response otherwise.
+func findOwnPost(ctx context.Context, c *gin.Context, client *mongo.Client) (models.Post, bool) {
+	var post models.Post
+
+	userId, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return post, false
+	}
+	userObjId, err := bson.ObjectIDFromHex(userId.(string))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user id"})
+		return post, false
+	}
+
+	postObjId, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post id"})
+		return post, false
+	}
+
+	err = database.OpenCollection("posts", client).FindOne(
+		ctx,
+		bson.M{"_id": postObjId, "author_id": userObjId},
+	).Decode(&post)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+			return post, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch post"})
+		return post, false
+	}
+	return post, true
+}
+
+// GetAutosave returns a post for its editor, together with the autosaved
+// edits not yet saved, if any.
+func GetAutosave(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		post, ok := findOwnPost(ctx, c, client)
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"post":     post,
+			"autosave": post.Autosave,
+		})
+	}
+}
+
+// AutosavePost keeps the editor's work. A draft is written through, since
+// nobody else can see it; edits to any other post are held aside in its
+// autosave until they are saved with UpdatePost, so readers never see a
+// half written change.
+//
+// The editor sends the updated_at of the post it loaded, and the saved_at of
+// the autosave it resumed or last wrote, if any. A save from an editor
+// working on an older copy, such as a second tab, is refused rather than
+// overwriting the newer one.
+func AutosavePost(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxAutosaveBytes)
+
+		var data struct {
+			Title     *string    `json:"title"`
+			Content   *string    `json:"content"`
+			ImageURL  *string    `json:"image_url"`
+			Tags      []string   `json:"tags"`
+			UpdatedAt *time.Time `json:"updated_at"`
+			SavedAt   *time.Time `json:"saved_at"`
+		}
+		if err := c.ShouldBindJSON(&data); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+		if data.UpdatedAt == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "updated_at of the loaded post is required"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		post, ok := findOwnPost(ctx, c, client)
+		if !ok {
+			return
+		}
+
+		// MongoDB keeps milliseconds, so the times handed back to the editor
+		// are cut to match what is stored.
+		now := time.Now().Truncate(time.Millisecond)
+		updatedAt := post.UpdatedAt
+		set := bson.M{}
+
+		if post.Status == utils.PostDraft {
+			if data.Title != nil && *data.Title != post.Title {
+				set["title"] = *data.Title
+				set["slug"] = GenerateUniqueSlug(*data.Title)
+			}
+			if data.Content != nil && *data.Content != post.Content {
+				rendered, err := utils.RenderMarkdown(*data.Content)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "Could not render content"})
+					return
+				}
+				set["content"] = *data.Content
+				for field, value := range rendered.Fields() {
+					set[field] = value
+				}
+			}
+			if data.ImageURL != nil {
+				set["image_url"] = *data.ImageURL
+			}
+			if data.Tags != nil {
+				set["tags"] = utils.NormalizeTags(data.Tags)
+			}
+			set["updated_at"] = now
+			updatedAt = now
+		} else {
+			// Fields left out keep what was autosaved before, or else what
+			// is live.
+			autosave := models.PostAutosave{
+				Title:    post.Title,
+				Content:  post.Content,
+				ImageURL: post.ImageURL,
+				Tags:     post.Tags,
+			}
+			if post.Autosave != nil {
+				autosave = *post.Autosave
+			}
+
+			if data.Title != nil {
+				autosave.Title = *data.Title
+			}
+			if data.Content != nil {
+				autosave.Content = *data.Content
+			}
+			if data.ImageURL != nil {
+				autosave.ImageURL = *data.ImageURL
+			}
+			if data.Tags != nil {
+				autosave.Tags = utils.NormalizeTags(data.Tags)
+			}
+			if autosave.Tags == nil {
+				autosave.Tags = []string{}
+			}
+			autosave.SavedAt = now
+			set["autosave"] = autosave
+		}
+
+		// A post saved, published or autosaved since the editor loaded it is
+		// left alone, and the editor is told to reload.
+		filter := bson.M{"_id": post.ID, "status": post.Status, "updated_at": *data.UpdatedAt}
+		if post.Status != utils.PostDraft {
+			if data.SavedAt != nil {
+				filter["autosave.saved_at"] = *data.SavedAt
+			} else {
+				filter["autosave"] = bson.M{"$exists": false}
+			}
+		}
+
+		result, err := database.OpenCollection("posts", client).UpdateOne(ctx, filter, bson.M{"$set": set})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Autosave failed"})
+			return
+		}
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Post changed since it was loaded"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"status":     post.Status,
+			"updated_at": updatedAt,
+			"saved_at":   now,
+		})
+	}
+}
+
+// DiscardAutosave drops autosaved edits, keeping the post as it was last
+// saved.
+func DiscardAutosave(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		post, ok := findOwnPost(ctx, c, client)
+		if !ok {
+			return
+		}
+
+		if _, err := database.OpenCollection("posts", client).UpdateOne(
+			ctx,
+			bson.M{"_id": post.ID},
+			bson.M{"$unset": bson.M{"autosave": ""}},
+		); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to discard autosave"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Autosave discarded"})
+	}
+}
@@ -2,24 +2,24 @@ package controllers
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"log"
 	"net/http"
-	"os"
 	"strings"
 
 	"time"
 
 	"github.com/ayushmehta03/devLink-backend/database"
 	"github.com/ayushmehta03/devLink-backend/models"
+	"github.com/ayushmehta03/devLink-backend/utils"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 
-func SendChatRequest(client *mongo.Client)gin.HandlerFunc{
+func SendChatRequest(client *mongo.Client, mailer utils.Mailer)gin.HandlerFunc{
 	return func(c *gin.Context){
 
 
@@ -67,6 +67,65 @@ func SendChatRequest(client *mongo.Client)gin.HandlerFunc{
 
 		chatCollection:=database.OpenCollection("chat_requests",client)
 
+		// Follows and requests outlive a ban or a pending deletion, so the
+		// receiver's standing is checked on every send.
+		var receiver models.User
+		err = database.OpenCollection("users", client).FindOne(ctx, bson.M{
+			"_id":                   receiverId,
+			"deletion_requested_at": bson.M{"$exists": false},
+		}, options.FindOne().SetProjection(bson.M{"restriction": 1})).Decode(&receiver)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message request"})
+			return
+		}
+		if utils.ActiveRestriction(receiver.Restriction, time.Now()) != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This user cannot receive messages"})
+			return
+		}
+
+		// Users who follow each other can message directly; the request
+		// message becomes the first message of their room.
+		rel, err := utils.GetFollowRelation(ctx, client, senderId, receiverId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message request"})
+			return
+		}
+
+		if rel.Mutual() {
+			roomId, err := findOrCreateRoom(ctx, client, senderId, receiverId)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create room"})
+				return
+			}
+
+			message, err := deliverMessage(ctx, client, roomId, senderId, body.Msg)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message"})
+				return
+			}
+
+			// Someone with the room open has just seen it.
+			if !inRoom(roomId.Hex(), receiverId.Hex()) {
+				go notifyChatRequest(client, mailer, models.ChatRequest{
+					SenderID:   senderId,
+					ReceiverID: receiverId,
+					Msg:        message.Content,
+				})
+			}
+
+			c.JSON(http.StatusCreated, gin.H{
+				"message": "Message sent",
+				"status":  "accepted",
+				"direct":  true,
+				"room_id": roomId.Hex(),
+			})
+			return
+		}
+
 
 		count,_:=chatCollection.CountDocuments(ctx,bson.M{
 			"sender_id":senderId,
@@ -96,12 +155,39 @@ func SendChatRequest(client *mongo.Client)gin.HandlerFunc{
 			return 
 		}
 
+		go notifyChatRequest(client, mailer, request)
+
 		c.JSON(http.StatusCreated,gin.H{"message":"Chat request sent "})
 
 	}
 }
 
 
+func notifyChatRequest(client *mongo.Client, mailer utils.Mailer, request models.ChatRequest) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	userCol := database.OpenCollection("users", client)
+
+	var sender, receiver models.User
+	if err := userCol.FindOne(ctx, bson.M{"_id": request.SenderID}).Decode(&sender); err != nil {
+		return
+	}
+	if err := userCol.FindOne(ctx, bson.M{"_id": request.ReceiverID}).Decode(&receiver); err != nil {
+		return
+	}
+
+	err := utils.SendTemplatedEmail(ctx, mailer, receiver.Email, receiver.Locale, utils.EmailChatRequest, utils.ChatRequestEmailData{
+		SenderName: sender.UserName,
+		Message:    request.Msg,
+		URL:        utils.AppURL("/chat"),
+	})
+	if err != nil {
+		log.Println("CHAT REQUEST EMAIL FAILED:", err)
+	}
+}
+
+
 func ReceiveChatRequest(client *mongo.Client)gin.HandlerFunc{
 	return func(c *gin.Context){
 
@@ -115,7 +201,10 @@ func ReceiveChatRequest(client *mongo.Client)gin.HandlerFunc{
 
 		receiverId,_:=bson.ObjectIDFromHex(userId.(string))
 
-		fmt.Println(receiverId)
+		page, ok := pageQuery(c, "created_at", true)
+		if !ok {
+			return
+		}
 		
 		ctx,cancel:=context.WithTimeout(context.Background(),10*time.Second)
 
@@ -125,10 +214,10 @@ func ReceiveChatRequest(client *mongo.Client)gin.HandlerFunc{
 
 
 
-		cursor,err:=chatCollection.Find(ctx,bson.M{
+		cursor,err:=chatCollection.Find(ctx,page.Filter(bson.M{
 			"receiver_id":receiverId,
 			"status": "pending",
-		})
+		}),page.FindOptions())
 
 		if err!=nil{
 			c.JSON(http.StatusInternalServerError,gin.H{"error":"Failed to fetch requests"})
@@ -138,14 +227,20 @@ func ReceiveChatRequest(client *mongo.Client)gin.HandlerFunc{
 		defer cursor.Close(ctx)
 
 
-		var requests []models.ChatRequest
+		requests := []models.ChatRequest{}
 
 		if err:=cursor.All(ctx,&requests);err!=nil{
 			c.JSON(http.StatusInternalServerError,gin.H{"error":"Failed to parse requests"})
 			return 
 		}
+		requests, nextCursor := utils.Next(page, requests, func(r models.ChatRequest) (any, bson.ObjectID) {
+			return r.CreatedAt, r.ID
+		})
 
-		c.JSON(http.StatusOK,requests)
+		c.JSON(http.StatusOK,gin.H{
+			"requests":    requests,
+			"next_cursor": nextCursor,
+		})
 	}
 }
 
@@ -186,7 +281,6 @@ func RespondChatRequest(client *mongo.Client) gin.HandlerFunc {
 		defer cancel()
 
 		reqCol := database.OpenCollection("chat_requests", client)
-		roomCol := database.OpenCollection("chat_rooms", client)
 
 		var req models.ChatRequest
 		if err := reqCol.FindOne(ctx, bson.M{"_id": reqObjectId}).Decode(&req); err != nil {
@@ -215,39 +309,15 @@ func RespondChatRequest(client *mongo.Client) gin.HandlerFunc {
 		}
 
 		if status == "accepted" {
-
-			var existing models.ChatRoom
-			err := roomCol.FindOne(ctx, bson.M{
-				"participants": bson.M{
-					"$all": []bson.ObjectID{req.SenderID, req.ReceiverID},
-				},
-			}).Decode(&existing)
-
-			if err == nil {
-				c.JSON(http.StatusOK, gin.H{
-					"status":  "accepted",
-					"room_id": existing.ID.Hex(),
-				})
-				return
-			}
-
-			room := models.ChatRoom{
-				ID: bson.NewObjectID(),
-				Participants: []bson.ObjectID{
-					req.SenderID,
-					req.ReceiverID,
-				},
-				CreatedAt: time.Now(),
-			}
-
-			if _, err := roomCol.InsertOne(ctx, room); err != nil {
+			roomId, err := findOrCreateRoom(ctx, client, req.SenderID, req.ReceiverID)
+			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create room"})
 				return
 			}
 
 			c.JSON(http.StatusOK, gin.H{
 				"status":  "accepted",
-				"room_id": room.ID.Hex(),
+				"room_id": roomId.Hex(),
 			})
 			return
 		}
@@ -257,6 +327,49 @@ func RespondChatRequest(client *mongo.Client) gin.HandlerFunc {
 }
 
 
+// findOrCreateRoom returns the room shared by two users, creating it on
+// first contact. The room is upserted on the pair's key, so two users
+// reaching out to each other at once still end up in one room.
+func findOrCreateRoom(ctx context.Context, client *mongo.Client, a, b bson.ObjectID) (bson.ObjectID, error) {
+	roomCol := database.OpenCollection("chat_rooms", client)
+	key := utils.RoomPairKey(a, b)
+
+	// A room from before pair keys is claimed on first use. Should the
+	// pair already have a keyed room, the unique index refuses the claim.
+	_, err := roomCol.UpdateOne(ctx, bson.M{
+		"participants": bson.M{"$all": []bson.ObjectID{a, b}},
+		"pair_key":     bson.M{"$exists": false},
+	}, bson.M{"$set": bson.M{"pair_key": key}})
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return bson.ObjectID{}, err
+	}
+
+	now := time.Now()
+	upsert := func() (models.ChatRoom, error) {
+		var room models.ChatRoom
+		err := roomCol.FindOneAndUpdate(ctx, bson.M{"pair_key": key}, bson.M{
+			"$setOnInsert": bson.M{
+				"participants":    []bson.ObjectID{a, b},
+				"last_message_at": now,
+				"created_at":      now,
+			},
+		}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&room)
+		return room, err
+	}
+
+	room, err := upsert()
+	// Concurrent upserts can both miss; the one that loses the insert
+	// finds the winner's room on its second try.
+	if mongo.IsDuplicateKeyError(err) {
+		room, err = upsert()
+	}
+	if err != nil {
+		return bson.ObjectID{}, err
+	}
+	return room.ID, nil
+}
+
+
 func ChatHistory(client *mongo.Client) gin.HandlerFunc{
 	return func(c *gin.Context){
 		userId,exists:=c.Get("user_id")
@@ -443,7 +556,16 @@ func GetChatRequestStatus(client *mongo.Client) gin.HandlerFunc {
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{"status": "none"})
+		rel, err := utils.GetFollowRelation(ctx, client, currentUser, otherUser)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch status"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"status":               "none",
+			"can_message_directly": rel.Mutual(),
+		})
 	}
 }
 
@@ -529,28 +651,39 @@ func GetChatRooms(client *mongo.Client) gin.HandlerFunc {
 			return
 		}
 
+		// Most recently active conversations first.
+		page, ok := pageQuery(c, "last_message_at", true)
+		if !ok {
+			return
+		}
+
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
 		roomCol := database.OpenCollection("chat_rooms", client)
 		msgCol := database.OpenCollection("messages", client)
 
-		cursor, err := roomCol.Find(ctx, bson.M{
+		cursor, err := roomCol.Find(ctx, page.Filter(bson.M{
 			"participants": uid,
-		})
+		}), page.FindOptions())
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rooms"})
 			return
 		}
 		defer cursor.Close(ctx)
 
-		var rooms []gin.H
+		chatRooms := []models.ChatRoom{}
+		if err := cursor.All(ctx, &chatRooms); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rooms"})
+			return
+		}
+		chatRooms, nextCursor := utils.Next(page, chatRooms, func(room models.ChatRoom) (any, bson.ObjectID) {
+			return room.LastMessageAt, room.ID
+		})
 
-		for cursor.Next(ctx) {
-			var room models.ChatRoom
-			if err := cursor.Decode(&room); err != nil {
-				continue
-			}
+		rooms := []gin.H{}
+
+		for _, room := range chatRooms {
 
 			var other bson.ObjectID
 			for _, p := range room.Participants {
@@ -593,7 +726,10 @@ func GetChatRooms(client *mongo.Client) gin.HandlerFunc {
 			})
 		}
 
-		c.JSON(http.StatusOK, gin.H{"rooms": rooms})
+		c.JSON(http.StatusOK, gin.H{
+			"rooms":       rooms,
+			"next_cursor": nextCursor,
+		})
 	}
 }
 
@@ -606,17 +742,12 @@ func GetWSToken() gin.HandlerFunc {
 			return
 		}
 
-		secret := os.Getenv("JWT_SECRET")
-
-		claims := jwt.MapClaims{
-			"user_id": userId.(string),
-			"exp":     time.Now().Add(2 * time.Minute).Unix(), 
-			"type":    "ws",
+		signed, err := utils.GeneratePurposeToken(userId.(string), "ws", 2*time.Minute)
+		if err != nil {
+			c.JSON(500, gin.H{"error": "failed to issue token"})
+			return
 		}
 
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		signed, _ := token.SignedString([]byte(secret))
-
 		c.JSON(200, gin.H{"token": signed})
 	}
 }
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestFindOrCreateRoomConcurrently(t *testing.T) {
+	client := testClient(t)
+	ctx := context.Background()
+
+	if err := utils.EnsureChatRoomIndexes(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+
+	a, b := bson.NewObjectID(), bson.NewObjectID()
+
+	var wg sync.WaitGroup
+	ids := make([]bson.ObjectID, 8)
+	errs := make([]error, len(ids))
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Half of the calls come from each side of the pair.
+			if i%2 == 0 {
+				ids[i], errs[i] = findOrCreateRoom(ctx, client, a, b)
+			} else {
+				ids[i], errs[i] = findOrCreateRoom(ctx, client, b, a)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range ids {
+		if errs[i] != nil {
+			t.Fatal(errs[i])
+		}
+		if ids[i] != ids[0] {
+			t.Errorf("call %d got room %s, want %s", i, ids[i].Hex(), ids[0].Hex())
+		}
+	}
+
+	if n, _ := database.OpenCollection("chat_rooms", client).CountDocuments(ctx, bson.M{}); n != 1 {
+		t.Errorf("rooms = %d, want 1", n)
+	}
+}
+
+func TestFindOrCreateRoomClaimsOlderRoom(t *testing.T) {
+	client := testClient(t)
+	ctx := context.Background()
+
+	if err := utils.EnsureChatRoomIndexes(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+
+	a, b := bson.NewObjectID(), bson.NewObjectID()
+	legacy := models.ChatRoom{
+		ID:            bson.NewObjectID(),
+		Participants:  []bson.ObjectID{a, b},
+		LastMessageAt: time.Now(),
+		CreatedAt:     time.Now(),
+	}
+	if _, err := database.OpenCollection("chat_rooms", client).InsertOne(ctx, legacy); err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := findOrCreateRoom(ctx, client, b, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != legacy.ID {
+		t.Errorf("got room %s, want the existing room %s", id.Hex(), legacy.ID.Hex())
+	}
+}
@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// A new export can be requested this long after the previous one.
+const dataExportCooldown = time.Hour
+
+func dataExportResponse(export models.DataExport) gin.H {
+	res := gin.H{
+		"id":         export.ID.Hex(),
+		"status":     export.Status,
+		"created_at": export.CreatedAt,
+	}
+
+	if export.CompletedAt != nil {
+		res["completed_at"] = export.CompletedAt
+	}
+	if export.Error != "" {
+		res["error"] = export.Error
+	}
+	if export.Status == utils.ExportReady {
+		res["file_name"] = export.FileName
+		res["size"] = export.Size
+		res["expires_at"] = export.ExpiresAt
+		res["download_url"] = "/api/auth/export/" + export.ID.Hex() + "/download"
+	}
+	return res
+}
+
+// RequestDataExport queues an archive of everything stored about the caller.
+// The archive is built in the background; poll GetDataExport for progress.
+func RequestDataExport(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		uid, err := bson.ObjectIDFromHex(userId.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		exportCollection := database.OpenCollection("data_exports", client)
+
+		var last models.DataExport
+		err = exportCollection.FindOne(
+			ctx,
+			bson.M{"user_id": uid},
+			options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+		).Decode(&last)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to request export"})
+			return
+		}
+
+		if err == nil {
+			if last.Status == utils.ExportPending || last.Status == utils.ExportRunning {
+				c.JSON(http.StatusAccepted, dataExportResponse(last))
+				return
+			}
+
+			if wait := time.Until(last.CreatedAt.Add(dataExportCooldown)); wait > 0 {
+				seconds := int(wait.Seconds()) + 1
+				c.Header("Retry-After", strconv.Itoa(seconds))
+				c.JSON(http.StatusTooManyRequests, gin.H{
+					"error":       "An export was requested recently. Please try again later.",
+					"retry_after": seconds,
+					"export":      dataExportResponse(last),
+				})
+				return
+			}
+		}
+
+		export := models.DataExport{
+			ID:        bson.NewObjectID(),
+			UserID:    uid,
+			Status:    utils.ExportPending,
+			CreatedAt: time.Now(),
+		}
+
+		// A request racing this one may have queued an export since the
+		// check above; the unique index on active exports lets only one in.
+		if _, err := exportCollection.InsertOne(ctx, export); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				err = exportCollection.FindOne(ctx, bson.M{
+					"user_id": uid,
+					"status":  bson.M{"$in": bson.A{utils.ExportPending, utils.ExportRunning}},
+				}).Decode(&export)
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to request export"})
+				return
+			}
+		}
+
+		c.JSON(http.StatusAccepted, dataExportResponse(export))
+	}
+}
+
+func findDataExport(ctx context.Context, c *gin.Context, client *mongo.Client) (models.DataExport, bool) {
+	var export models.DataExport
+
+	userId, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return export, false
+	}
+
+	uid, err := bson.ObjectIDFromHex(userId.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+		return export, false
+	}
+
+	exportId, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid export id"})
+		return export, false
+	}
+
+	if err := database.OpenCollection("data_exports", client).FindOne(ctx, bson.M{"_id": exportId, "user_id": uid}).Decode(&export); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Export not found"})
+		return export, false
+	}
+	return export, true
+}
+
+func GetDataExport(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		export, ok := findDataExport(ctx, c, client)
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, dataExportResponse(export))
+	}
+}
+
+func DownloadDataExport(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		export, ok := findDataExport(ctx, c, client)
+		if !ok {
+			return
+		}
+
+		if export.Status != utils.ExportReady || export.FileID == nil ||
+			(export.ExpiresAt != nil && time.Now().After(*export.ExpiresAt)) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Export is not ready for download"})
+			return
+		}
+
+		// The download may outlast the lookup timeout on slow connections.
+		stream, err := utils.ExportBucket(client).OpenDownloadStream(c.Request.Context(), *export.FileID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Export file not found"})
+			return
+		}
+		defer stream.Close()
+
+		c.DataFromReader(http.StatusOK, stream.GetFile().Length, "application/zip", stream, map[string]string{
+			"Content-Disposition": `attachment; filename="` + export.FileName + `"`,
+			"Cache-Control":       "no-store",
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestRequestDataExportQueuesOnce(t *testing.T) {
+	client := testClient(t)
+	ctx := context.Background()
+
+	if err := utils.EnsureDataExportIndexes(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+
+	uid := bson.NewObjectID()
+	handler := RequestDataExport(client)
+
+	// serveJSON only fails the test on a body it cannot encode, which an
+	// empty map is not, so it is safe to call from the goroutines.
+	var wg sync.WaitGroup
+	recs := make([]*httptest.ResponseRecorder, 8)
+	for i := range recs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			recs[i] = serveJSON(t, handler, uid.Hex(), gin.H{})
+		}(i)
+	}
+	wg.Wait()
+
+	var first any
+	for i, rec := range recs {
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("request %d: status = %d, want 202: %s", i, rec.Code, rec.Body)
+		}
+		id := decodeBody(t, rec)["id"]
+		if i == 0 {
+			first = id
+		} else if id != first {
+			t.Errorf("request %d got export %v, want %v", i, id, first)
+		}
+	}
+
+	if n, _ := database.OpenCollection("data_exports", client).CountDocuments(ctx, bson.M{"user_id": uid}); n != 1 {
+		t.Errorf("exports = %d, want 1", n)
+	}
+}
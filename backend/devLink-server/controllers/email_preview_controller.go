@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func ListEmailPreviews() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"templates": utils.EmailTemplates,
+			"locales":   utils.SupportedLocales,
+		})
+	}
+}
+
+// PreviewEmail renders a transactional email with sample data. Use
+// ?locale=es to switch language and ?format=text|subject for the other parts.
+func PreviewEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("name")
+
+		data, ok := utils.SampleEmailData(name)
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Unknown email template"})
+			return
+		}
+
+		msg, err := utils.RenderEmail(name, c.Query("locale"), data)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		switch c.DefaultQuery("format", "html") {
+		case "text":
+			c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(msg.Text))
+		case "subject":
+			c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(msg.Subject))
+		default:
+			c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(msg.HTML))
+		}
+	}
+}
@@ -0,0 +1,410 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	maxFeedPageSize = 50
+
+	// Only recent posts are ranked, and only the newest candidates of those,
+	// which bounds the work per page regardless of collection size.
+	feedMaxAge         = 30 * 24 * time.Hour
+	feedCandidateLimit = 1000
+	feedMaxFollowees   = 1000
+
+	feedSourceFollowing = "following"
+	feedSourceTrending  = "trending"
+)
+
+// Ranking weights in hours of recency: a post from a followed author ranks
+// like one a day newer, each matching tag (up to three) like six hours newer.
+// The score depends on neither the current time nor counters such as views,
+// so a post keeps its place while the reader pages through the feed.
+const (
+	feedAuthorBoost   = 24
+	feedTagBoost      = 6
+	feedMaxTagMatches = 3
+)
+
+// The sort field of each feed source. A cursor is issued for one field,
+// which tells which source the next page continues. The fallback pages by
+// the stored trending score rather than live view counts, which move
+// between requests and would repeat or skip posts across pages.
+var feedSortFields = map[string]string{
+	feedSourceFollowing: "score",
+	feedSourceTrending:  "trending_score",
+}
+
+// feedPage reads ?limit= and ?cursor= for whichever source the cursor
+// belongs to, the following feed when there is none.
+func feedPage(c *gin.Context, source string) (utils.Page, error) {
+	page, err := utils.NewPage(feedSortFields[source], true, c.Query("limit"), c.Query("cursor"))
+	page.Limit = min(page.Limit, maxFeedPageSize)
+	return page, err
+}
+
+type FeedPostResponse struct {
+	models.Post
+	Author PostAuthor `json:"author"`
+	Reason string     `json:"reason"`
+}
+
+type rankedPost struct {
+	models.Post `bson:",inline"`
+	Followed    bool    `bson:"followed"`
+	TagHits     int     `bson:"tag_hits"`
+	Score       float64 `bson:"score"`
+}
+
+// GetFeed ranks recent posts from followed authors and tags. Users with an
+// empty graph, or whose graph has nothing recent, get trending posts.
+func GetFeed(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		uid, err := bson.ObjectIDFromHex(userId.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+			return
+		}
+
+		source := feedSourceFollowing
+		page, err := feedPage(c, source)
+		if err != nil {
+			source = feedSourceTrending
+			if page, err = feedPage(c, source); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+				return
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var ranked []rankedPost
+
+		if source == feedSourceFollowing {
+			ranked, err = followingFeed(ctx, client, uid, page)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feed"})
+				return
+			}
+
+			// Nothing to follow yet: start over on trending posts.
+			if _, _, continued := page.After(); !continued && len(ranked) == 0 {
+				source = feedSourceTrending
+				page, _ = feedPage(c, source)
+			}
+		}
+
+		if source == feedSourceTrending {
+			ranked, err = trendingFeed(ctx, client, page)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feed"})
+				return
+			}
+		}
+
+		ranked, nextCursor := utils.Next(page, ranked, func(post rankedPost) (any, bson.ObjectID) {
+			if source == feedSourceTrending {
+				return post.TrendingScore, post.ID
+			}
+			return post.Score, post.ID
+		})
+
+		response, err := feedResponse(ctx, client, ranked, source)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feed"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"posts":       response,
+			"source":      source,
+			"next_cursor": nextCursor,
+		})
+	}
+}
+
+func followingFeed(ctx context.Context, client *mongo.Client, uid bson.ObjectID, page utils.Page) ([]rankedPost, error) {
+	var user models.User
+	if err := database.OpenCollection("users", client).FindOne(
+		ctx,
+		bson.M{"_id": uid},
+		options.FindOne().SetProjection(bson.M{"followed_tags": 1}),
+	).Decode(&user); err != nil {
+		return nil, err
+	}
+
+	followCursor, err := database.OpenCollection("follows", client).Find(
+		ctx,
+		bson.M{"follower_id": uid},
+		options.Find().
+			SetProjection(bson.M{"followee_id": 1}).
+			SetSort(bson.D{{Key: "created_at", Value: -1}}).
+			SetLimit(feedMaxFollowees),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	follows := []models.Follow{}
+	if err := followCursor.All(ctx, &follows); err != nil {
+		return nil, err
+	}
+
+	authors := []bson.ObjectID{}
+	for _, f := range follows {
+		authors = append(authors, f.FolloweeID)
+	}
+
+	tags := user.FollowedTags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	if len(authors) == 0 && len(tags) == 0 {
+		return nil, nil
+	}
+
+	match, err := withoutHiddenAuthors(ctx, client, bson.M{
+		"published":    true,
+		"published_at": bson.M{"$gte": time.Now().Add(-feedMaxAge)},
+		"$or": []bson.M{
+			{"author_id": bson.M{"$in": authors}},
+			{"tags": bson.M{"$in": tags}, "author_id": bson.M{"$ne": uid}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$sort": bson.D{{Key: "published_at", Value: -1}}},
+		{"$limit": feedCandidateLimit},
+		{"$addFields": bson.M{
+			"followed": bson.M{"$in": bson.A{"$author_id", authors}},
+			"tag_hits": bson.M{"$size": bson.M{"$setIntersection": bson.A{
+				bson.M{"$ifNull": bson.A{"$tags", bson.A{}}},
+				tags,
+			}}},
+		}},
+		{"$addFields": bson.M{
+			"score": bson.M{"$add": bson.A{
+				bson.M{"$divide": bson.A{bson.M{"$toLong": "$published_at"}, 3600000}},
+				bson.M{"$cond": bson.A{"$followed", feedAuthorBoost, 0}},
+				bson.M{"$multiply": bson.A{feedTagBoost, bson.M{"$min": bson.A{"$tag_hits", feedMaxTagMatches}}}},
+			}},
+		}},
+	}
+
+	if after := page.Filter(bson.M{}); len(after) > 0 {
+		pipeline = append(pipeline, bson.M{"$match": after})
+	}
+
+	pipeline = append(pipeline,
+		bson.M{"$sort": page.Sort()},
+		bson.M{"$limit": page.Limit + 1},
+	)
+
+	aggCursor, err := database.OpenCollection("posts", client).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	ranked := []rankedPost{}
+	err = aggCursor.All(ctx, &ranked)
+	return ranked, err
+}
+
+// trendingFeed pages published posts by their trending score. Posts
+// published since the last refresh have none yet and join on the next.
+func trendingFeed(ctx context.Context, client *mongo.Client, page utils.Page) ([]rankedPost, error) {
+	filter, err := withoutHiddenAuthors(ctx, client, bson.M{
+		"published":      true,
+		"trending_score": bson.M{"$exists": true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	postCursor, err := database.OpenCollection("posts", client).Find(ctx, page.Filter(filter), page.FindOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	posts := []models.Post{}
+	if err := postCursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+
+	ranked := []rankedPost{}
+	for _, post := range posts {
+		ranked = append(ranked, rankedPost{Post: post})
+	}
+	return ranked, nil
+}
+
+func feedResponse(ctx context.Context, client *mongo.Client, ranked []rankedPost, source string) ([]FeedPostResponse, error) {
+	ids := []bson.ObjectID{}
+	for _, post := range ranked {
+		ids = append(ids, post.AuthorID)
+	}
+
+	authors, err := loadPostAuthors(ctx, client, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	response := []FeedPostResponse{}
+	for _, post := range ranked {
+		reason := source
+		if source == feedSourceFollowing && !post.Followed {
+			reason = "tag"
+		}
+
+		response = append(response, FeedPostResponse{
+			Post:   post.Post,
+			Author: authors.get(post.AuthorID),
+			Reason: reason,
+		})
+	}
+	return response, nil
+}
+
+func GetFollowedTags(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		uid, err := bson.ObjectIDFromHex(userId.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var user models.User
+		if err := database.OpenCollection("users", client).FindOne(
+			ctx,
+			bson.M{"_id": uid},
+			options.FindOne().SetProjection(bson.M{"followed_tags": 1}),
+		).Decode(&user); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		tags := user.FollowedTags
+		if tags == nil {
+			tags = []string{}
+		}
+
+		c.JSON(http.StatusOK, gin.H{"tags": tags})
+	}
+}
+
+func FollowTag(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		uid, err := bson.ObjectIDFromHex(userId.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+			return
+		}
+
+		tag := utils.NormalizeTag(c.Param("tag"))
+		if tag == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		// The size guard keeps the list bounded without a read-modify-write.
+		res, err := database.OpenCollection("users", client).UpdateOne(
+			ctx,
+			bson.M{
+				"_id": uid,
+				"$or": []bson.M{
+					{"followed_tags": tag},
+					{"followed_tags." + strconv.Itoa(utils.MaxFollowedTags-1): bson.M{"$exists": false}},
+				},
+			},
+			bson.M{"$addToSet": bson.M{"followed_tags": tag}},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to follow tag"})
+			return
+		}
+		if res.MatchedCount == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You can follow at most 50 tags"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"tag": tag, "is_following": true})
+	}
+}
+
+func UnfollowTag(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		uid, err := bson.ObjectIDFromHex(userId.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+			return
+		}
+
+		tag := utils.NormalizeTag(c.Param("tag"))
+		if tag == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if _, err := database.OpenCollection("users", client).UpdateOne(
+			ctx,
+			bson.M{"_id": uid},
+			bson.M{"$pull": bson.M{"followed_tags": tag}},
+		); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unfollow tag"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"tag": tag, "is_following": false})
+	}
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func getFeed(t *testing.T, handler gin.HandlerFunc, userId string, query url.Values) map[string]any {
+	t.Helper()
+
+	router := gin.New()
+	router.GET("/", func(c *gin.Context) {
+		c.Set("user_id", userId)
+		handler(c)
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?"+query.Encode(), nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	return decodeBody(t, rec)
+}
+
+func feedTitles(body map[string]any) []string {
+	titles := []string{}
+	posts, _ := body["posts"].([]any)
+	for _, post := range posts {
+		titles = append(titles, post.(map[string]any)["title"].(string))
+	}
+	return titles
+}
+
+func TestGetFeedFallsBackToTrending(t *testing.T) {
+	client := testClient(t)
+	ctx := context.Background()
+
+	reader := insertTOTPUser(t, client, "")
+	author := bson.NewObjectID()
+
+	now := time.Now()
+	postCollection := database.OpenCollection("posts", client)
+	insert := func(title string, views int64, age time.Duration) bson.ObjectID {
+		publishedAt := now.Add(-age)
+		post := models.Post{
+			ID:          bson.NewObjectID(),
+			Title:       title,
+			Content:     title,
+			AuthorID:    author,
+			Status:      utils.PostPublished,
+			Published:   true,
+			PublishedAt: &publishedAt,
+			ViewCount:   views,
+			CreatedAt:   publishedAt,
+			UpdatedAt:   publishedAt,
+		}
+		if _, err := postCollection.InsertOne(ctx, post); err != nil {
+			t.Fatal(err)
+		}
+		return post.ID
+	}
+
+	insert("popular", 1000, time.Hour)
+	insert("fresh", 10, 0)
+	quiet := insert("quiet", 1, 2*time.Hour)
+
+	if err := utils.RefreshTrendingScores(ctx, client, now); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := GetFeed(client)
+
+	body := getFeed(t, handler, reader.Id.Hex(), url.Values{"limit": {"2"}})
+	if body["source"] != feedSourceTrending {
+		t.Fatalf("source = %v, want %s", body["source"], feedSourceTrending)
+	}
+	if got := feedTitles(body); len(got) != 2 || got[0] != "popular" || got[1] != "fresh" {
+		t.Fatalf("first page = %v, want [popular fresh]", got)
+	}
+	cursor, _ := body["next_cursor"].(string)
+	if cursor == "" {
+		t.Fatal("no cursor for the second page")
+	}
+
+	// Views between pages do not move posts until the next refresh.
+	if _, err := postCollection.UpdateOne(ctx, bson.M{"_id": quiet}, bson.M{"$set": bson.M{"view_count": 100000}}); err != nil {
+		t.Fatal(err)
+	}
+
+	body = getFeed(t, handler, reader.Id.Hex(), url.Values{"limit": {"2"}, "cursor": {cursor}})
+	if got := feedTitles(body); len(got) != 1 || got[0] != "quiet" {
+		t.Errorf("second page = %v, want [quiet]", got)
+	}
+}
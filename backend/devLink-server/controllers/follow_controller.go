@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// followTarget reads the caller and the :userId they want to (un)follow.
+func followTarget(c *gin.Context) (bson.ObjectID, bson.ObjectID, bool) {
+	userId, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return bson.ObjectID{}, bson.ObjectID{}, false
+	}
+
+	uid, err := bson.ObjectIDFromHex(userId.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+		return bson.ObjectID{}, bson.ObjectID{}, false
+	}
+
+	targetId, err := bson.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return bson.ObjectID{}, bson.ObjectID{}, false
+	}
+
+	if uid == targetId {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot follow yourself"})
+		return bson.ObjectID{}, bson.ObjectID{}, false
+	}
+	return uid, targetId, true
+}
+
+func FollowUser(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uid, targetId, ok := followTarget(c)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		count, err := database.OpenCollection("users", client).CountDocuments(ctx, bson.M{
+			"_id":                   targetId,
+			"deletion_requested_at": bson.M{"$exists": false},
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to follow user"})
+			return
+		}
+		if count == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		_, err = database.OpenCollection("follows", client).UpdateOne(
+			ctx,
+			bson.M{"follower_id": uid, "followee_id": targetId},
+			bson.M{"$setOnInsert": bson.M{"created_at": time.Now()}},
+			options.UpdateOne().SetUpsert(true),
+		)
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to follow user"})
+			return
+		}
+
+		rel, err := utils.GetFollowRelation(ctx, client, uid, targetId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to follow user"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"is_following": true,
+			"follows_you":  rel.FollowedBy,
+			"mutual":       rel.Mutual(),
+		})
+	}
+}
+
+func UnfollowUser(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uid, targetId, ok := followTarget(c)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if _, err := database.OpenCollection("follows", client).DeleteOne(ctx, bson.M{
+			"follower_id": uid,
+			"followee_id": targetId,
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unfollow user"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"is_following": false, "mutual": false})
+	}
+}
+
+type FollowUserResponse struct {
+	ID           string    `json:"id"`
+	Handle       string    `json:"handle,omitempty"`
+	Username     string    `json:"username"`
+	ProfileImage string    `json:"profile_image,omitempty"`
+	FollowedAt   time.Time `json:"followed_at"`
+}
+
+// listFollows pages through one direction of a user's follow edges. field
+// names the side that matches the user and other the side that is listed.
+func listFollows(client *mongo.Client, field, other string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userObjId, err := bson.ObjectIDFromHex(c.Param("userId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		// Newest follows first.
+		page, ok := pageQuery(c, "created_at", true)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		followCollection := database.OpenCollection("follows", client)
+		filter := bson.M{field: userObjId}
+
+		total, err := followCollection.CountDocuments(ctx, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch follows"})
+			return
+		}
+
+		cursor, err := followCollection.Find(ctx, page.Filter(bson.M{field: userObjId}), page.FindOptions())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch follows"})
+			return
+		}
+
+		follows := []models.Follow{}
+		if err := cursor.All(ctx, &follows); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch follows"})
+			return
+		}
+		follows, nextCursor := utils.Next(page, follows, func(f models.Follow) (any, bson.ObjectID) {
+			return f.CreatedAt, f.ID
+		})
+
+		ids := []bson.ObjectID{}
+		for _, f := range follows {
+			if other == "follower_id" {
+				ids = append(ids, f.FollowerID)
+			} else {
+				ids = append(ids, f.FolloweeID)
+			}
+		}
+
+		users := map[bson.ObjectID]models.User{}
+		if len(ids) > 0 {
+			userCursor, err := database.OpenCollection("users", client).Find(ctx, bson.M{
+				"_id":                   bson.M{"$in": ids},
+				"deletion_requested_at": bson.M{"$exists": false},
+			}, options.Find().SetProjection(bson.M{"name": 1, "handle": 1, "profile_image": 1}))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch follows"})
+				return
+			}
+
+			found := []models.User{}
+			userCursor.All(ctx, &found)
+			for _, u := range found {
+				users[u.Id] = u
+			}
+		}
+
+		response := []FollowUserResponse{}
+		for i, id := range ids {
+			u, ok := users[id]
+			if !ok {
+				continue
+			}
+			response = append(response, FollowUserResponse{
+				ID:           u.Id.Hex(),
+				Handle:       u.Handle,
+				Username:     u.UserName,
+				ProfileImage: u.ProfileImage,
+				FollowedAt:   follows[i].CreatedAt,
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"users":       response,
+			"total":       total,
+			"next_cursor": nextCursor,
+		})
+	}
+}
+
+func GetFollowers(client *mongo.Client) gin.HandlerFunc {
+	return listFollows(client, "followee_id", "follower_id")
+}
+
+func GetFollowing(client *mongo.Client) gin.HandlerFunc {
+	return listFollows(client, "follower_id", "followee_id")
+}
@@ -0,0 +1,17 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// GetJWKS publishes the public keys that currently verify DevLink tokens so
+// other services can check them without sharing a secret.
+func GetJWKS() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, gin.H{"keys": utils.Keys().JWKS()})
+	}
+}
@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const magicNonceCookie = "magic_nonce"
+
+func setMagicNonceCookie(c *gin.Context, nonce string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     magicNonceCookie,
+		Value:    nonce,
+		MaxAge:   maxAge,
+		Path:     "/api/auth/magic",
+		Domain:   cookieDomain(),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+}
+
+func RequestMagicLink(client *mongo.Client, mailer utils.Mailer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		var req struct {
+			Email string `json:"email" validate:"required,email"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data"})
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email"})
+			return
+		}
+
+		// Reuse the nonce of an earlier request from this browser so that
+		// asking twice does not invalidate the first link.
+		nonce, err := c.Cookie(magicNonceCookie)
+		if err != nil || len(nonce) < 32 {
+			nonce, err = utils.GenerateRandomToken(32)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+				return
+			}
+		}
+		setMagicNonceCookie(c, nonce, int(utils.MagicLinkTTL.Seconds()))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		userCollection := database.OpenCollection("users", client)
+
+		var user models.User
+		err = userCollection.FindOne(ctx, bson.M{"email": req.Email}).Decode(&user)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		// Unverified accounts must finish OTP verification first, and links
+		// are rate limited per account. Neither case is revealed to the caller.
+		if err == nil && user.IsVerified &&
+			time.Since(utils.LastMagicLinkSentAt(ctx, client, user.Id)) >= otpResendDelay {
+
+			token, err := utils.IssueMagicLink(ctx, client, user.Id, nonce)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+				return
+			}
+
+			go func(email, locale string) {
+				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				defer cancel()
+
+				if err := utils.SendTemplatedEmail(ctx, mailer, email, locale, utils.EmailMagicLink, utils.MagicLinkEmailData{
+					URL:          utils.AppURL("/magic?token=" + url.QueryEscape(token)),
+					ValidMinutes: int(utils.MagicLinkTTL.Minutes()),
+				}); err != nil {
+					log.Println("MAGIC LINK EMAIL FAILED:", err)
+				}
+			}(user.Email, user.Locale)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "If an account exists for this email, a login link has been sent.",
+		})
+	}
+}
+
+func MagicLinkLogin(client *mongo.Client) gin.HandlerFunc {
+	ipLimiter := utils.NewAttemptLimiter(client, "magic_ip", utils.IPLockoutPolicy)
+
+	return func(c *gin.Context) {
+
+		var req struct {
+			Token string `json:"token"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil || req.Token == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		ipKey := attemptKey{ipLimiter, c.ClientIP()}
+		if !allowAttempt(ctx, c, ipKey) {
+			return
+		}
+
+		nonce, _ := c.Cookie(magicNonceCookie)
+
+		userId, err := utils.RedeemMagicLink(ctx, client, req.Token, nonce)
+		if err != nil {
+			if errors.Is(err, utils.ErrInvalidMagicLink) {
+				recordFailedAttempt(ctx, ipKey)
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": "Login link is invalid, expired, already used or was opened in a different browser",
+					"code":  "MAGIC_LINK_INVALID",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Login failed"})
+			return
+		}
+
+		setMagicNonceCookie(c, "", -1)
+
+		var user models.User
+		if err := database.OpenCollection("users", client).FindOne(ctx, bson.M{"_id": userId}).Decode(&user); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
+			return
+		}
+
+		if rejectRestricted(c, user) {
+			return
+		}
+
+		if user.TOTPEnabled {
+			mfaToken, err := utils.GeneratePurposeToken(user.Id.Hex(), "mfa", mfaTokenTTL)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{
+				"message":      "Two-factor authentication required",
+				"mfa_required": true,
+				"mfa_token":    mfaToken,
+			})
+			return
+		}
+
+		if err := startSession(ctx, c, client, user); err != nil {
+			respondSessionError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Login successful",
+		})
+	}
+}
@@ -0,0 +1,352 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	mfaTokenTTL       = 5 * time.Minute
+	recoveryCodeCount = 10
+)
+
+// mfaClock is the time TOTP codes are checked against. Tests replace it
+// with a fixed clock.
+var mfaClock = time.Now
+
+// verifySecondFactor accepts either a TOTP code or an unused recovery code
+// and consumes it so it cannot be replayed.
+func verifySecondFactor(ctx context.Context, userCollection *mongo.Collection, user models.User, code, recoveryCode string, now time.Time) (bool, error) {
+	if code != "" {
+		step, ok := utils.ValidateTOTP(user.TOTPSecret, code, now)
+		if !ok {
+			return false, nil
+		}
+
+		res, err := userCollection.UpdateOne(
+			ctx,
+			bson.M{
+				"_id": user.Id,
+				"$or": []bson.M{
+					{"totp_last_step": bson.M{"$lt": step}},
+					{"totp_last_step": bson.M{"$exists": false}},
+				},
+			},
+			bson.M{"$set": bson.M{"totp_last_step": step}},
+		)
+		if err != nil {
+			return false, err
+		}
+		return res.ModifiedCount > 0, nil
+	}
+
+	if recoveryCode != "" {
+		hash := utils.HashToken(utils.NormalizeRecoveryCode(recoveryCode))
+
+		res, err := userCollection.UpdateOne(
+			ctx,
+			bson.M{"_id": user.Id, "recovery_codes": hash},
+			bson.M{"$pull": bson.M{"recovery_codes": hash}},
+		)
+		if err != nil {
+			return false, err
+		}
+		return res.ModifiedCount > 0, nil
+	}
+
+	return false, nil
+}
+
+func SetupTOTP(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		uid, err := bson.ObjectIDFromHex(userId.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		userCollection := database.OpenCollection("users", client)
+
+		var user models.User
+		if err := userCollection.FindOne(ctx, bson.M{"_id": uid}).Decode(&user); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		if user.TOTPEnabled {
+			c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication already enabled"})
+			return
+		}
+
+		secret, err := utils.GenerateTOTPSecret()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+			return
+		}
+
+		if _, err := userCollection.UpdateOne(ctx, bson.M{"_id": uid}, bson.M{
+			"$set": bson.M{
+				"totp_pending_secret": secret,
+				"updated_at":          time.Now(),
+			},
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start enrollment"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"secret":      secret,
+			"otpauth_uri": utils.TOTPURI(secret, user.Email),
+		})
+	}
+}
+
+func ConfirmTOTP(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		uid, err := bson.ObjectIDFromHex(userId.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+			return
+		}
+
+		var req struct {
+			Code string `json:"code"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		userCollection := database.OpenCollection("users", client)
+
+		var user models.User
+		if err := userCollection.FindOne(ctx, bson.M{"_id": uid}).Decode(&user); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		if user.TOTPPendingSecret == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No two-factor enrollment in progress"})
+			return
+		}
+
+		step, ok := utils.ValidateTOTP(user.TOTPPendingSecret, req.Code, mfaClock())
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+			return
+		}
+
+		codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+			return
+		}
+
+		hashes := make([]string, 0, len(codes))
+		for _, code := range codes {
+			hashes = append(hashes, utils.HashToken(code))
+		}
+
+		if _, err := userCollection.UpdateOne(ctx, bson.M{"_id": uid}, bson.M{
+			"$set": bson.M{
+				"totp_enabled":   true,
+				"totp_secret":    user.TOTPPendingSecret,
+				"totp_last_step": step,
+				"recovery_codes": hashes,
+				"updated_at":     time.Now(),
+			},
+			"$unset": bson.M{
+				"totp_pending_secret": "",
+			},
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":        "Two-factor authentication enabled",
+			"recovery_codes": codes,
+		})
+	}
+}
+
+func DisableTOTP(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		uid, err := bson.ObjectIDFromHex(userId.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+			return
+		}
+
+		var req struct {
+			Password     string `json:"password"`
+			Code         string `json:"code"`
+			RecoveryCode string `json:"recovery_code"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		userCollection := database.OpenCollection("users", client)
+
+		var user models.User
+		if err := userCollection.FindOne(ctx, bson.M{"_id": uid}).Decode(&user); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		if !user.TOTPEnabled {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+			return
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Password is incorrect"})
+			return
+		}
+
+		ok, err := verifySecondFactor(ctx, userCollection, user, req.Code, req.RecoveryCode, mfaClock())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Verification failed"})
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+			return
+		}
+
+		if _, err := userCollection.UpdateOne(ctx, bson.M{"_id": uid}, bson.M{
+			"$set": bson.M{
+				"totp_enabled": false,
+				"updated_at":   time.Now(),
+			},
+			"$unset": bson.M{
+				"totp_secret":         "",
+				"totp_pending_secret": "",
+				"totp_last_step":      "",
+				"recovery_codes":      "",
+			},
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+	}
+}
+
+func VerifyMFA(client *mongo.Client) gin.HandlerFunc {
+	accountLimiter := utils.NewAttemptLimiter(client, "mfa_account", utils.AccountLockoutPolicy)
+
+	return func(c *gin.Context) {
+
+		var req struct {
+			MFAToken     string `json:"mfa_token"`
+			Code         string `json:"code"`
+			RecoveryCode string `json:"recovery_code"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		claims, err := utils.VerifyPurposeToken(req.MFAToken, "mfa")
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired MFA token"})
+			return
+		}
+
+		uid, err := bson.ObjectIDFromHex(claims.UserID)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired MFA token"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		accountKey := attemptKey{accountLimiter, uid.Hex()}
+		if !allowAttempt(ctx, c, accountKey) {
+			return
+		}
+
+		userCollection := database.OpenCollection("users", client)
+
+		var user models.User
+		if err := userCollection.FindOne(ctx, bson.M{"_id": uid}).Decode(&user); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
+			return
+		}
+
+		if !user.TOTPEnabled {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+			return
+		}
+
+		ok, err := verifySecondFactor(ctx, userCollection, user, req.Code, req.RecoveryCode, mfaClock())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Verification failed"})
+			return
+		}
+		if !ok {
+			recordFailedAttempt(ctx, accountKey)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+			return
+		}
+
+		resetAttempts(ctx, accountKey)
+
+		if rejectRestricted(c, user) {
+			return
+		}
+
+		if err := startSession(ctx, c, client, user); err != nil {
+			respondSessionError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Login successful",
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// useMFAClock fixes the time TOTP codes are checked against.
+func useMFAClock(t *testing.T, now time.Time) {
+	t.Helper()
+
+	mfaClock = func() time.Time { return now }
+	t.Cleanup(func() { mfaClock = time.Now })
+}
+
+// insertTOTPUser stores a verified user with two-factor authentication on.
+func insertTOTPUser(t *testing.T, client *mongo.Client, secret string, recoveryCodes ...string) models.User {
+	t.Helper()
+
+	hashes := []string{}
+	for _, code := range recoveryCodes {
+		hashes = append(hashes, utils.HashToken(code))
+	}
+
+	user := models.User{
+		Id:            bson.NewObjectID(),
+		UserName:      "mfa user",
+		Email:         "mfa@example.com",
+		Role:          "user",
+		IsVerified:    true,
+		TOTPEnabled:   true,
+		TOTPSecret:    secret,
+		RecoveryCodes: hashes,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	user.UserId = user.Id.Hex()
+
+	if _, err := database.OpenCollection("users", client).InsertOne(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func mfaToken(t *testing.T, user models.User) string {
+	t.Helper()
+
+	token, err := utils.GeneratePurposeToken(user.Id.Hex(), "mfa", mfaTokenTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestVerifyMFARejectsOtherTokens(t *testing.T) {
+	useTestKeys(t)
+
+	login, err := utils.GeneratePurposeToken(bson.NewObjectID().Hex(), "magic_link", mfaTokenTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, token := range []string{"", "not-a-token", login} {
+		rec := serveJSON(t, VerifyMFA(nil), "", gin.H{"mfa_token": token, "code": "123456"})
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("token %q: status = %d, want 401", token, rec.Code)
+		}
+	}
+}
+
+func TestVerifyMFAAcceptsCodeOnce(t *testing.T) {
+	client := testClient(t)
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := insertTOTPUser(t, client, secret)
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	useMFAClock(t, now)
+
+	code, err := utils.TOTPCode(secret, utils.TOTPStep(now))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := gin.H{"mfa_token": mfaToken(t, user), "code": code}
+
+	rec := serveJSON(t, VerifyMFA(client), "", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if len(rec.Result().Cookies()) == 0 {
+		t.Error("no session cookies set")
+	}
+
+	rec = serveJSON(t, VerifyMFA(client), "", body)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("replayed code: status = %d, want 401", rec.Code)
+	}
+}
+
+func TestVerifyMFARejectsCodeOutsideWindow(t *testing.T) {
+	client := testClient(t)
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := insertTOTPUser(t, client, secret)
+
+	issued := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	code, err := utils.TOTPCode(secret, utils.TOTPStep(issued))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	useMFAClock(t, issued.Add(2*utils.TOTPPeriod*time.Second))
+
+	rec := serveJSON(t, VerifyMFA(client), "", gin.H{"mfa_token": mfaToken(t, user), "code": code})
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", rec.Code)
+	}
+}
+
+func TestVerifyMFARecoveryCodeIsSingleUse(t *testing.T) {
+	client := testClient(t)
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := insertTOTPUser(t, client, secret, "abcd-efgh")
+
+	body := gin.H{"mfa_token": mfaToken(t, user), "recovery_code": "ABCD EFGH"}
+
+	rec := serveJSON(t, VerifyMFA(client), "", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+
+	rec = serveJSON(t, VerifyMFA(client), "", body)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("reused recovery code: status = %d, want 401", rec.Code)
+	}
+}
+
+func TestConfirmTOTPEnablesTwoFactor(t *testing.T) {
+	client := testClient(t)
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := insertTOTPUser(t, client, "")
+	userCollection := database.OpenCollection("users", client)
+	if _, err := userCollection.UpdateOne(context.Background(), bson.M{"_id": user.Id}, bson.M{
+		"$set":   bson.M{"totp_enabled": false, "totp_pending_secret": secret},
+		"$unset": bson.M{"totp_secret": ""},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	useMFAClock(t, now)
+
+	code, err := utils.TOTPCode(secret, utils.TOTPStep(now))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := serveJSON(t, ConfirmTOTP(client), user.Id.Hex(), gin.H{"code": code})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if codes, _ := decodeBody(t, rec)["recovery_codes"].([]any); len(codes) != recoveryCodeCount {
+		t.Errorf("got %d recovery codes, want %d", len(codes), recoveryCodeCount)
+	}
+
+	var stored models.User
+	if err := userCollection.FindOne(context.Background(), bson.M{"_id": user.Id}).Decode(&stored); err != nil {
+		t.Fatal(err)
+	}
+	if !stored.TOTPEnabled || stored.TOTPSecret != secret || stored.TOTPPendingSecret != "" {
+		t.Error("two-factor authentication not enabled with the pending secret")
+	}
+}
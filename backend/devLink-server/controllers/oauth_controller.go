@@ -0,0 +1,412 @@
+package controllers
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	oauthFlowCookie = "oauth_flow"
+	oauthFlowTTL    = 10 * time.Minute
+)
+
+var (
+	errOAuthNoEmail       = errors.New("provider did not share an email address")
+	errOAuthAccountExists = errors.New("an account with this email already exists")
+)
+
+func setOAuthFlowCookie(c *gin.Context, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oauthFlowCookie,
+		Value:    value,
+		MaxAge:   maxAge,
+		Path:     "/api/auth/oauth",
+		Domain:   cookieDomain(),
+		HttpOnly: true,
+		Secure:   true,
+		// The provider sends the browser back with a top-level GET, which
+		// Lax cookies survive.
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// safeRedirectPath only allows paths on the frontend, never another origin.
+func safeRedirectPath(path string) string {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.HasPrefix(path, "/\\") {
+		return "/dashboard"
+	}
+	return path
+}
+
+func oauthRedirect(c *gin.Context, path string, params url.Values) {
+	target := utils.AppURL(path)
+	if len(params) > 0 {
+		target += "?" + params.Encode()
+	}
+	c.Redirect(http.StatusFound, target)
+}
+
+func oauthError(c *gin.Context, code string) {
+	oauthRedirect(c, "/login", url.Values{"error": {code}})
+}
+
+func ListOAuthProviders(providers map[string]utils.OAuthProvider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		names := make([]string, 0, len(providers))
+		for name := range providers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		c.JSON(http.StatusOK, gin.H{"providers": names})
+	}
+}
+
+func OAuthStart(providers map[string]utils.OAuthProvider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provider, ok := providers[c.Param("provider")]
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Unknown login provider"})
+			return
+		}
+
+		state, err := utils.GenerateRandomToken(24)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+			return
+		}
+
+		verifier, err := utils.GenerateRandomToken(32)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+			return
+		}
+
+		authURL, err := provider.AuthCodeURL(state, utils.PKCEChallenge(verifier))
+		if err != nil {
+			log.Println("OAUTH START FAILED:", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Login provider unavailable"})
+			return
+		}
+
+		redirect := safeRedirectPath(c.Query("redirect"))
+
+		setOAuthFlowCookie(c, strings.Join([]string{
+			provider.Name(),
+			state,
+			verifier,
+			base64.RawURLEncoding.EncodeToString([]byte(redirect)),
+		}, "."), int(oauthFlowTTL.Seconds()))
+
+		c.Redirect(http.StatusFound, authURL)
+	}
+}
+
+func OAuthCallback(client *mongo.Client, mailer utils.Mailer, providers map[string]utils.OAuthProvider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provider, ok := providers[c.Param("provider")]
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Unknown login provider"})
+			return
+		}
+
+		flow, err := c.Cookie(oauthFlowCookie)
+		setOAuthFlowCookie(c, "", -1)
+		if err != nil {
+			oauthError(c, "oauth_state")
+			return
+		}
+
+		parts := strings.Split(flow, ".")
+		if len(parts) != 4 || parts[0] != provider.Name() ||
+			subtle.ConstantTimeCompare([]byte(parts[1]), []byte(c.Query("state"))) != 1 {
+			oauthError(c, "oauth_state")
+			return
+		}
+
+		verifier := parts[2]
+		redirectBytes, _ := base64.RawURLEncoding.DecodeString(parts[3])
+		redirect := safeRedirectPath(string(redirectBytes))
+
+		if c.Query("error") != "" || c.Query("code") == "" {
+			oauthError(c, "oauth_denied")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+
+		identity, err := provider.Exchange(ctx, c.Query("code"), verifier)
+		if err != nil {
+			log.Println("OAUTH EXCHANGE FAILED:", err)
+			oauthError(c, "oauth_failed")
+			return
+		}
+
+		locale := utils.ResolveLocale(c.GetHeader("Accept-Language"))
+
+		user, otp, err := resolveOAuthUser(ctx, client, identity, locale)
+		if err != nil {
+			switch {
+			case errors.Is(err, errOAuthNoEmail):
+				oauthError(c, "oauth_no_email")
+			case errors.Is(err, errOAuthAccountExists):
+				oauthError(c, "oauth_account_exists")
+			default:
+				log.Println("OAUTH LOGIN FAILED:", err)
+				oauthError(c, "oauth_failed")
+			}
+			return
+		}
+
+		if restriction := utils.ActiveRestriction(user.Restriction, time.Now()); restriction != nil {
+			oauthError(c, "account_"+restriction.Status)
+			return
+		}
+
+		if !user.IsVerified {
+			params := url.Values{"email": {user.Email}}
+			if otp != "" {
+				if err := utils.SendOTPEmail(ctx, mailer, user.Email, user.Locale, otp); err != nil {
+					log.Println("OTP EMAIL FAILED:", err)
+					params.Set("autoResend", "1")
+				}
+			} else {
+				params.Set("autoResend", "1")
+			}
+			oauthRedirect(c, "/verify-otp", params)
+			return
+		}
+
+		if user.TOTPEnabled {
+			mfaToken, err := utils.GeneratePurposeToken(user.Id.Hex(), "mfa", mfaTokenTTL)
+			if err != nil {
+				oauthError(c, "oauth_failed")
+				return
+			}
+			oauthRedirect(c, "/login", url.Values{"mfa_token": {mfaToken}, "redirect": {redirect}})
+			return
+		}
+
+		if err := startSession(ctx, c, client, user); err != nil {
+			if errors.Is(err, utils.ErrAccountDeleted) {
+				oauthError(c, "account_deleted")
+				return
+			}
+			oauthError(c, "oauth_failed")
+			return
+		}
+
+		oauthRedirect(c, redirect, nil)
+	}
+}
+
+// resolveOAuthUser finds the account linked to identity, links it to an
+// existing account with the same verified email, or creates a new account.
+// For new accounts whose email the provider has not verified it also returns
+// the OTP that must be mailed to the user.
+func resolveOAuthUser(ctx context.Context, client *mongo.Client, identity utils.OAuthIdentity, locale string) (models.User, string, error) {
+	userCollection := database.OpenCollection("users", client)
+	identityCollection := database.OpenCollection("oauth_identities", client)
+
+	now := time.Now()
+
+	var linked models.OAuthIdentity
+	err := identityCollection.FindOne(ctx, bson.M{
+		"provider": identity.Provider,
+		"subject":  identity.Subject,
+	}).Decode(&linked)
+
+	if err == nil {
+		var user models.User
+		err := userCollection.FindOne(ctx, bson.M{"_id": linked.UserID}).Decode(&user)
+		if err == nil {
+			identityCollection.UpdateOne(ctx, bson.M{"_id": linked.ID}, bson.M{
+				"$set": bson.M{"last_used_at": now, "email": identity.Email},
+			})
+
+			if !user.IsVerified && identity.EmailVerified && strings.EqualFold(identity.Email, user.Email) {
+				if _, err := userCollection.UpdateOne(ctx, bson.M{"_id": user.Id}, bson.M{
+					"$set":   bson.M{"is_verified": true, "updated_at": now},
+					"$unset": bson.M{"otp_hash": "", "otp_expiry": "", "otp_attempts": ""},
+				}); err != nil {
+					return models.User{}, "", err
+				}
+				user.IsVerified = true
+			}
+
+			return user, "", nil
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return models.User{}, "", err
+		}
+
+		// The account behind this identity is gone, start over.
+		identityCollection.DeleteOne(ctx, bson.M{"_id": linked.ID})
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		return models.User{}, "", err
+	}
+
+	if identity.Email == "" {
+		return models.User{}, "", errOAuthNoEmail
+	}
+
+	var user models.User
+	err = userCollection.FindOne(ctx, bson.M{"email": identity.Email}).Decode(&user)
+
+	switch {
+	case err == nil:
+		if !identity.EmailVerified {
+			return models.User{}, "", errOAuthAccountExists
+		}
+
+		if !user.IsVerified {
+			// Nobody proved they own this address before, so a password set
+			// at registration must not keep working.
+			if _, err := userCollection.UpdateOne(ctx, bson.M{"_id": user.Id}, bson.M{
+				"$set":   bson.M{"is_verified": true, "password": "", "updated_at": now},
+				"$unset": bson.M{"otp_hash": "", "otp_expiry": "", "otp_attempts": ""},
+			}); err != nil {
+				return models.User{}, "", err
+			}
+			user.IsVerified = true
+		}
+
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return createOAuthUser(ctx, client, identity, locale)
+
+	default:
+		return models.User{}, "", err
+	}
+
+	if err := linkOAuthIdentity(ctx, identityCollection, user.Id, identity, now); err != nil {
+		return models.User{}, "", err
+	}
+
+	return user, "", nil
+}
+
+func createOAuthUser(ctx context.Context, client *mongo.Client, identity utils.OAuthIdentity, locale string) (models.User, string, error) {
+	now := time.Now()
+
+	avatarURL := identity.AvatarURL
+	if avatarURL == "" {
+		avatarURL = fmt.Sprintf(
+			"https://api.dicebear.com/7.x/initials/svg?seed=%s",
+			url.QueryEscape(identity.Email),
+		)
+	}
+
+	handleSeed := identity.Username
+	if handleSeed == "" {
+		handleSeed = oauthUserName(identity)
+	}
+
+	handle, err := utils.GenerateHandle(ctx, client, handleSeed)
+	if err != nil {
+		return models.User{}, "", err
+	}
+
+	user := models.User{
+		Id:           bson.NewObjectID(),
+		UserId:       bson.NewObjectID().Hex(),
+		UserName:     oauthUserName(identity),
+		Handle:       handle,
+		Email:        identity.Email,
+		IsVerified:   identity.EmailVerified,
+		Role:         "user",
+		Locale:       locale,
+		ProfileImage: avatarURL,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	otp := ""
+	if !user.IsVerified {
+		otp = GenerateOTP()
+		otpHash, err := HashPassword(otp)
+		if err != nil {
+			return models.User{}, "", err
+		}
+		user.OTPHash = otpHash
+		user.OTPExpiry = now.Add(10 * time.Minute)
+		user.OTPSentAt = now
+	}
+
+	if _, err := database.OpenCollection("users", client).InsertOne(ctx, user); err != nil {
+		return models.User{}, "", err
+	}
+
+	identityCollection := database.OpenCollection("oauth_identities", client)
+	if err := linkOAuthIdentity(ctx, identityCollection, user.Id, identity, now); err != nil {
+		return models.User{}, "", err
+	}
+
+	return user, otp, nil
+}
+
+func linkOAuthIdentity(ctx context.Context, identityCollection *mongo.Collection, userId bson.ObjectID, identity utils.OAuthIdentity, now time.Time) error {
+	_, err := identityCollection.InsertOne(ctx, models.OAuthIdentity{
+		ID:         bson.NewObjectID(),
+		UserID:     userId,
+		Provider:   identity.Provider,
+		Subject:    identity.Subject,
+		Email:      identity.Email,
+		Username:   identity.Username,
+		CreatedAt:  now,
+		LastUsedAt: now,
+	})
+	return err
+}
+
+// oauthUserName turns the provider's username into one that satisfies the
+// 5 to 22 character rule on models.User.
+func oauthUserName(identity utils.OAuthIdentity) string {
+	candidates := []string{identity.Username, identity.Name}
+	if local, _, ok := strings.Cut(identity.Email, "@"); ok {
+		candidates = append(candidates, local)
+	}
+
+	name := ""
+	for _, candidate := range candidates {
+		name = strings.Map(func(r rune) rune {
+			switch {
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+				return r
+			case r == ' ' || r == '.':
+				return '_'
+			}
+			return -1
+		}, candidate)
+		if name != "" {
+			break
+		}
+	}
+
+	if name == "" {
+		name = "dev"
+	}
+	for len(name) < 5 {
+		name += "_dev"
+	}
+	if len(name) > 22 {
+		name = name[:22]
+	}
+	return name
+}
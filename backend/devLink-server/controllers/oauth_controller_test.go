@@ -0,0 +1,234 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const fakeIdPCode = "fake-code"
+
+// fakeIdP is a local OpenID Connect provider. It issues fakeIdPCode for
+// any authorization request and hands out its userinfo once the code is
+// redeemed with the verifier matching the PKCE challenge.
+type fakeIdP struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	challenge string
+	userInfo  map[string]any
+}
+
+func newFakeIdP(t *testing.T, userInfo map[string]any) *fakeIdP {
+	t.Helper()
+
+	idp := &fakeIdP{userInfo: userInfo}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"authorization_endpoint": idp.URL + "/authorize",
+			"token_endpoint":         idp.URL + "/token",
+			"userinfo_endpoint":      idp.URL + "/userinfo",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		idp.mu.Lock()
+		challenge := idp.challenge
+		idp.mu.Unlock()
+
+		if r.PostForm.Get("code") != fakeIdPCode || utils.PKCEChallenge(r.PostForm.Get("code_verifier")) != challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "fake-access-token", "token_type": "Bearer"})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fake-access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(idp.userInfo)
+	})
+
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+// oauthFlow drives a login through the fake provider: it starts the flow,
+// lets the provider answer with callbackQuery (the state is filled in) and
+// returns the callback's redirect.
+func oauthFlow(t *testing.T, client *mongo.Client, idp *fakeIdP, callbackQuery url.Values) *url.URL {
+	t.Helper()
+
+	t.Setenv("FRONTEND_URL", "http://app.test")
+
+	providers := map[string]utils.OAuthProvider{
+		"fake": &utils.OIDCProvider{
+			OAuthEndpoints: utils.OAuthEndpoints{
+				ClientID:     "client",
+				ClientSecret: "secret",
+				RedirectURL:  "http://api.test/api/auth/oauth/fake/callback",
+				Scopes:       []string{"openid", "email"},
+			},
+			ProviderName: "fake",
+			Issuer:       idp.URL,
+		},
+	}
+	mailer := &utils.LogMailer{Logger: log.New(io.Discard, "", 0)}
+
+	router := gin.New()
+	router.GET("/api/auth/oauth/:provider", OAuthStart(providers))
+	router.GET("/api/auth/oauth/:provider/callback", OAuthCallback(client, mailer, providers))
+
+	start := httptest.NewRecorder()
+	router.ServeHTTP(start, httptest.NewRequest(http.MethodGet, "/api/auth/oauth/fake?redirect=/posts/new", nil))
+	if start.Code != http.StatusFound {
+		t.Fatalf("start: status = %d, want 302", start.Code)
+	}
+
+	authURL, err := url.Parse(start.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authURL.Query().Get("code_challenge_method") != "S256" {
+		t.Fatal("start: authorization request without PKCE")
+	}
+
+	idp.mu.Lock()
+	idp.challenge = authURL.Query().Get("code_challenge")
+	idp.mu.Unlock()
+
+	if callbackQuery.Get("state") == "" {
+		callbackQuery.Set("state", authURL.Query().Get("state"))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/oauth/fake/callback?"+callbackQuery.Encode(), nil)
+	for _, cookie := range start.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+
+	callback := httptest.NewRecorder()
+	router.ServeHTTP(callback, req)
+	if callback.Code != http.StatusFound {
+		t.Fatalf("callback: status = %d, want 302: %s", callback.Code, callback.Body)
+	}
+
+	location, err := url.Parse(callback.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return location
+}
+
+func TestOAuthCallbackErrors(t *testing.T) {
+	idp := newFakeIdP(t, map[string]any{"sub": "1", "email": "dev@example.com", "email_verified": true})
+
+	tests := []struct {
+		name  string
+		query url.Values
+		want  string
+	}{
+		{"state mismatch", url.Values{"state": {"forged"}, "code": {fakeIdPCode}}, "oauth_state"},
+		{"denied", url.Values{"error": {"access_denied"}}, "oauth_denied"},
+		{"bad code", url.Values{"code": {"stolen-code"}}, "oauth_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location := oauthFlow(t, nil, idp, tt.query)
+			if location.Path != "/login" || location.Query().Get("error") != tt.want {
+				t.Errorf("redirect = %s, want /login?error=%s", location, tt.want)
+			}
+		})
+	}
+}
+
+func TestOAuthCallbackCreatesAccount(t *testing.T) {
+	client := testClient(t)
+
+	idp := newFakeIdP(t, map[string]any{
+		"sub":                "idp-user-1",
+		"email":              "new.dev@example.com",
+		"email_verified":     "true",
+		"preferred_username": "newdev",
+	})
+
+	location := oauthFlow(t, client, idp, url.Values{"code": {fakeIdPCode}})
+	if location.Host != "app.test" || location.Path != "/posts/new" {
+		t.Fatalf("redirect = %s, want http://app.test/posts/new", location)
+	}
+
+	ctx := context.Background()
+
+	var user models.User
+	if err := database.OpenCollection("users", client).FindOne(ctx, bson.M{"email": "new.dev@example.com"}).Decode(&user); err != nil {
+		t.Fatal(err)
+	}
+	if !user.IsVerified {
+		t.Error("account from a verified provider email is not verified")
+	}
+
+	count, err := database.OpenCollection("oauth_identities", client).CountDocuments(ctx, bson.M{
+		"provider": "fake", "subject": "idp-user-1", "user_id": user.Id,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("linked identities = %d, want 1", count)
+	}
+
+	// Signing in again reuses the account.
+	oauthFlow(t, client, idp, url.Values{"code": {fakeIdPCode}})
+	if n, _ := database.OpenCollection("users", client).CountDocuments(ctx, bson.M{}); n != 1 {
+		t.Errorf("users = %d after a second sign in, want 1", n)
+	}
+}
+
+func TestOAuthCallbackAsksForSecondFactor(t *testing.T) {
+	client := testClient(t)
+
+	user := insertTOTPUser(t, client, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ")
+	if _, err := database.OpenCollection("oauth_identities", client).InsertOne(context.Background(), models.OAuthIdentity{
+		ID:         bson.NewObjectID(),
+		UserID:     user.Id,
+		Provider:   "fake",
+		Subject:    "idp-user-2",
+		Email:      user.Email,
+		CreatedAt:  time.Now(),
+		LastUsedAt: time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	idp := newFakeIdP(t, map[string]any{"sub": "idp-user-2", "email": user.Email, "email_verified": true})
+
+	location := oauthFlow(t, client, idp, url.Values{"code": {fakeIdPCode}})
+	if location.Path != "/login" || location.Query().Get("redirect") != "/posts/new" {
+		t.Fatalf("redirect = %s, want /login with the original redirect", location)
+	}
+
+	claims, err := utils.VerifyPurposeToken(location.Query().Get("mfa_token"), "mfa")
+	if err != nil || claims.UserID != user.Id.Hex() {
+		t.Errorf("redirect carries no MFA token for the user: %v", err)
+	}
+}
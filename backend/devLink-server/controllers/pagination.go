@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/ayushmehta03/devLink-backend/models"
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// pageQuery reads ?limit= and ?cursor= for a list sorted by field.
+func pageQuery(c *gin.Context, field string, desc bool) (utils.Page, bool) {
+	page, err := utils.NewPage(field, desc, c.Query("limit"), c.Query("cursor"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+		return page, false
+	}
+	return page, true
+}
+
+// postPageKey is the page key of post lists ordered by published_at.
+func postPageKey(post models.Post) (any, bson.ObjectID) {
+	if post.PublishedAt == nil {
+		return post.CreatedAt, post.ID
+	}
+	return *post.PublishedAt, post.ID
+}
@@ -0,0 +1,274 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const resetOTPTTL = 10 * time.Minute
+
+// Compared against when no reset code exists so that unknown emails take
+// as long to reject as wrong codes.
+var dummyOTPHash, _ = HashPassword("000000")
+
+func ForgotPassword(client *mongo.Client, mailer utils.Mailer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		var req struct {
+			Email string `json:"email" validate:"required,email"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data"})
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		userCollection := database.OpenCollection("users", client)
+
+		otp := GenerateOTP()
+		otpHash, err := HashPassword(otp)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		var user models.User
+		err = userCollection.FindOneAndUpdate(ctx, bson.M{"email": req.Email}, bson.M{
+			"$set": bson.M{
+				"reset_otp_hash":   otpHash,
+				"reset_otp_expiry": time.Now().Add(resetOTPTTL),
+				"updated_at":       time.Now(),
+			},
+			"$unset": bson.M{
+				"reset_otp_attempts": "",
+			},
+		}).Decode(&user)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if err == nil {
+			go func(email, locale string) {
+				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				defer cancel()
+
+				if err := utils.SendPasswordResetEmail(ctx, mailer, email, locale, otp); err != nil {
+					log.Println("RESET EMAIL FAILED:", err)
+				}
+			}(user.Email, user.Locale)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "If an account exists for this email, a reset code has been sent.",
+		})
+	}
+}
+
+func ResetPassword(client *mongo.Client) gin.HandlerFunc {
+	ipLimiter := utils.NewAttemptLimiter(client, "reset_ip", utils.IPLockoutPolicy)
+
+	return func(c *gin.Context) {
+
+		var req struct {
+			Email       string `json:"email" validate:"required,email"`
+			OTP         string `json:"otp" validate:"required"`
+			NewPassword string `json:"new_password" validate:"required,min=6"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data"})
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		ipKey := attemptKey{ipLimiter, c.ClientIP()}
+		if !allowAttempt(ctx, c, ipKey) {
+			return
+		}
+
+		userCollection := database.OpenCollection("users", client)
+
+		var user models.User
+		found := userCollection.FindOne(ctx, bson.M{"email": req.Email}).Decode(&user) == nil
+
+		// The attempt is counted before the code is compared; without a
+		// code or an attempt left, the dummy hash keeps the timing the same.
+		reserved := false
+		var attempts int64
+		if found && user.ResetOTPHash != "" {
+			var err error
+			user, attempts, err = reserveOTPAttempt(ctx, userCollection, user.Id, "reset_otp_hash", "reset_otp_attempts")
+			if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Password reset failed"})
+				return
+			}
+			reserved = err == nil
+		}
+
+		otpHash := dummyOTPHash
+		if reserved {
+			otpHash = user.ResetOTPHash
+		}
+
+		otpErr := bcrypt.CompareHashAndPassword([]byte(otpHash), []byte(strings.TrimSpace(req.OTP)))
+
+		if !reserved || time.Now().After(user.ResetOTPExpiry) || otpErr != nil {
+			recordFailedAttempt(ctx, ipKey)
+
+			if reserved && otpErr != nil {
+				burnOTP(ctx, userCollection, user.Id, attempts, "reset_otp_hash", "reset_otp_expiry", "reset_otp_attempts")
+			}
+
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset code"})
+			return
+		}
+
+		hashedPassword, err := HashPassword(req.NewPassword)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		res, err := userCollection.UpdateOne(
+			ctx,
+			bson.M{"_id": user.Id, "reset_otp_hash": user.ResetOTPHash},
+			bson.M{
+				"$set": bson.M{
+					"password":   hashedPassword,
+					"updated_at": time.Now(),
+				},
+				"$unset": bson.M{
+					"reset_otp_hash":     "",
+					"reset_otp_expiry":   "",
+					"reset_otp_attempts": "",
+				},
+			},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Password reset failed"})
+			return
+		}
+
+		if res.ModifiedCount == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset code"})
+			return
+		}
+
+		if _, err := utils.RevokeUserSessions(ctx, client, user.Id); err != nil {
+			log.Println("SESSION REVOCATION FAILED:", err)
+		}
+
+		clearAuthCookie(c)
+		clearRefreshCookie(c)
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Password reset successful. Please log in again.",
+		})
+	}
+}
+
+func ChangePassword(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		uid, err := bson.ObjectIDFromHex(userId.(string))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+			return
+		}
+
+		var req struct {
+			CurrentPassword string `json:"current_password" validate:"required"`
+			NewPassword     string `json:"new_password" validate:"required,min=6"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data"})
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Validation failed",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		userCollection := database.OpenCollection("users", client)
+
+		var user models.User
+		if err := userCollection.FindOne(ctx, bson.M{"_id": uid}).Decode(&user); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+			return
+		}
+
+		hashedPassword, err := HashPassword(req.NewPassword)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if _, err := userCollection.UpdateOne(ctx, bson.M{"_id": uid}, bson.M{
+			"$set": bson.M{
+				"password":   hashedPassword,
+				"updated_at": time.Now(),
+			},
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Password change failed"})
+			return
+		}
+
+		if currentId, err := bson.ObjectIDFromHex(c.GetString("session_id")); err == nil {
+			if _, err := utils.RevokeUserSessions(ctx, client, uid, currentId); err != nil {
+				log.Println("SESSION REVOCATION FAILED:", err)
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Password changed"})
+	}
+}
@@ -3,7 +3,9 @@ package controllers
 import (
 	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"regexp"
 	"strings"
@@ -20,18 +22,37 @@ import (
 
 type PostAuthor struct {
 	ID           bson.ObjectID `json:"id"`
+	Handle       string        `json:"handle,omitempty"`
 	Username     string        `json:"username"`
 	ProfileImage string        `json:"profile_image"`
+	Deleted      bool          `json:"deleted,omitempty"`
 }
 
-type HomePostResponse struct {
+type PostResponse struct {
 	models.Post
 	Author PostAuthor `json:"author"`
 }
 
-type PostResponse struct {
-	models.Post
-	Author PostAuthor `json:"author"`
+// PostDetailResponse adds the rendered body, which lists leave out.
+type PostDetailResponse struct {
+	PostResponse
+	ContentHTML string            `json:"content_html"`
+	TOC         []models.TOCEntry `json:"toc"`
+}
+
+// withoutHiddenAuthors narrows a public post listing to authors who are
+// neither banned nor awaiting account deletion. Listings fail rather than
+// show hidden authors when the lookup does.
+func withoutHiddenAuthors(ctx context.Context, client *mongo.Client, filter bson.M) (bson.M, error) {
+	hidden, err := utils.HiddenAuthorIDs(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(hidden) > 0 {
+		filter["author_id"] = bson.M{"$nin": hidden}
+	}
+	return filter, nil
 }
 
 
@@ -41,13 +62,18 @@ func GetHomeFeed(client *mongo.Client) gin.HandlerFunc {
 		defer cancel()
 
 		postCol := database.OpenCollection("posts", client)
-		userCol := database.OpenCollection("users", client)
+
+		filter, err := withoutHiddenAuthors(ctx, client, bson.M{"published": true})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch home feed"})
+			return
+		}
 
 		cursor, err := postCol.Find(
 			ctx,
-			bson.M{"published": true},
+			filter,
 			options.Find().
-				SetSort(bson.D{{Key: "created_at", Value: -1}}).
+				SetSort(bson.D{{Key: "published_at", Value: -1}}).
 				SetLimit(3),
 		)
 		if err != nil {
@@ -61,22 +87,10 @@ func GetHomeFeed(client *mongo.Client) gin.HandlerFunc {
 			return
 		}
 
-		var response []HomePostResponse
-
-		for _, post := range posts {
-			var user models.User
-			if err := userCol.FindOne(ctx, bson.M{"_id": post.AuthorID}).Decode(&user); err != nil {
-				continue
-			}
-
-			response = append(response, HomePostResponse{
-				Post: post,
-				Author: PostAuthor{
-					ID:           user.Id,
-					Username:     user.UserName,
-					ProfileImage: user.ProfileImage,
-				},
-			})
+		response, err := hydratePosts(ctx, client, posts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load authors"})
+			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{"posts": response})
@@ -89,11 +103,18 @@ func GetTrendingPosts(client *mongo.Client) gin.HandlerFunc {
 		defer cancel()
 
 		postCol := database.OpenCollection("posts", client)
-		userCol := database.OpenCollection("users", client)
+
+		filter, err := withoutHiddenAuthors(ctx, client, bson.M{"published": true})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch trending posts",
+			})
+			return
+		}
 
 		cursor, err := postCol.Find(
 			ctx,
-			bson.M{"published": true},
+			filter,
 			options.Find().
 				SetSort(bson.D{{Key: "view_count", Value: -1}}).
 				SetLimit(10),
@@ -113,22 +134,10 @@ func GetTrendingPosts(client *mongo.Client) gin.HandlerFunc {
 			return
 		}
 
-		var response []HomePostResponse
-
-		for _, post := range posts {
-			var user models.User
-			if err := userCol.FindOne(ctx, bson.M{"_id": post.AuthorID}).Decode(&user); err != nil {
-				continue
-			}
-
-			response = append(response, HomePostResponse{
-				Post: post,
-				Author: PostAuthor{
-					ID:           user.Id,
-					Username:     user.UserName,
-					ProfileImage: user.ProfileImage,
-				},
-			})
+		response, err := hydratePosts(ctx, client, posts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load authors"})
+			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
@@ -139,7 +148,7 @@ func GetTrendingPosts(client *mongo.Client) gin.HandlerFunc {
 
 
 
-func CreatePost(client *mongo.Client) gin.HandlerFunc {
+func CreatePost(client *mongo.Client, searcher utils.Searcher) gin.HandlerFunc {
     return func(c *gin.Context) {
         userId, exists := c.Get("user_id")
         if !exists {
@@ -172,15 +181,42 @@ func CreatePost(client *mongo.Client) gin.HandlerFunc {
         post.ID = bson.NewObjectID()
         post.AuthorID = authorObjId
         post.Slug = GenerateUniqueSlug(post.Title)
+        post.Tags = utils.NormalizeTags(post.Tags)
         post.ViewCount = 0
+
+        rendered, err := utils.RenderMarkdown(post.Content)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Could not render content"})
+            return
+        }
+        rendered.Apply(&post)
         post.CreatedAt = time.Now()
         post.UpdatedAt = time.Now()
 
+        // Clients that predate statuses only send published.
+        status := post.Status
+        if status == "" {
+            status = utils.PostDraft
+            if post.Published {
+                status = utils.PostPublished
+            }
+        }
+        post.PublishedAt = nil
+        post.Autosave = nil
+        if err := utils.SetPostStatus(&post, status, post.PublishAt, post.CreatedAt); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+
         if _, err := database.OpenCollection("posts", client).InsertOne(ctx, post); err != nil {
             c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create post"})
             return
         }
 
+        if err := searcher.Index(ctx, post); err != nil {
+            log.Println("SEARCH INDEX FAILED:", err)
+        }
+
         c.JSON(http.StatusCreated, PostResponse{
             Post: post,
             Author: PostAuthor{
@@ -198,13 +234,23 @@ func GetAllPosts(client *mongo.Client) gin.HandlerFunc {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
+		page, ok := pageQuery(c, "published_at", true)
+		if !ok {
+			return
+		}
+
 		postCol := database.OpenCollection("posts", client)
-		userCol := database.OpenCollection("users", client)
+
+		filter, err := withoutHiddenAuthors(ctx, client, bson.M{"published": true})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts"})
+			return
+		}
 
 		cursor, err := postCol.Find(
 			ctx,
-			bson.M{"published": true},
-			options.Find().SetSort(bson.M{"created_at": -1}),
+			page.Filter(filter),
+			page.FindOptions(),
 		)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts"})
@@ -216,26 +262,18 @@ func GetAllPosts(client *mongo.Client) gin.HandlerFunc {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse posts"})
 			return
 		}
+		posts, nextCursor := utils.Next(page, posts, postPageKey)
 
-		var response []PostResponse
-
-		for _, post := range posts {
-			var user models.User
-			if err := userCol.FindOne(ctx, bson.M{"_id": post.AuthorID}).Decode(&user); err != nil {
-				continue
-			}
-
-			response = append(response, PostResponse{
-				Post: post,
-				Author: PostAuthor{
-					ID:           user.Id,
-					Username:     user.UserName,
-					ProfileImage: user.ProfileImage,
-				},
-			})
+		response, err := hydratePosts(ctx, client, posts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load authors"})
+			return
 		}
 
-		c.JSON(http.StatusOK, response)
+		c.JSON(http.StatusOK, gin.H{
+			"posts":       response,
+			"next_cursor": nextCursor,
+		})
 	}
 }
 
@@ -248,7 +286,6 @@ func GetPostBySlug(client *mongo.Client) gin.HandlerFunc {
 		defer cancel()
 
 		postCol := database.OpenCollection("posts", client)
-		userCol := database.OpenCollection("users", client)
 
 		var post models.Post
 		err := postCol.FindOneAndUpdate(
@@ -262,25 +299,38 @@ func GetPostBySlug(client *mongo.Client) gin.HandlerFunc {
 			return
 		}
 
-		var user models.User
-		if err := userCol.FindOne(ctx, bson.M{"_id": post.AuthorID}).Decode(&user); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Author not found"})
+		authors, err := loadPostAuthors(ctx, client, []bson.ObjectID{post.AuthorID})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load author"})
 			return
 		}
 
-		c.JSON(http.StatusOK, PostResponse{
-			Post: post,
-			Author: PostAuthor{
-				ID:           user.Id,
-				Username:     user.UserName,
-				ProfileImage: user.ProfileImage,
-			},
+		// Posts saved before rendering existed are rendered on the fly until
+		// the backfill reaches them.
+		if post.ContentHTML == "" && post.Content != "" {
+			rendered, err := utils.RenderMarkdown(post.Content)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render post"})
+				return
+			}
+			rendered.Apply(&post)
+		}
+
+		toc := post.TOC
+		if toc == nil {
+			toc = []models.TOCEntry{}
+		}
+
+		c.JSON(http.StatusOK, PostDetailResponse{
+			PostResponse: PostResponse{Post: post, Author: authors.get(post.AuthorID)},
+			ContentHTML:  post.ContentHTML,
+			TOC:          toc,
 		})
 	}
 }
 
 
-func UpdatePost(client *mongo.Client) gin.HandlerFunc {
+func UpdatePost(client *mongo.Client, searcher utils.Searcher) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		postId := c.Param("id")
@@ -314,11 +364,13 @@ func UpdatePost(client *mongo.Client) gin.HandlerFunc {
 		}
 
 		var data struct {
-			Title     *string  `json:"title"`
-			Content   *string  `json:"content"`
-			ImageURL  *string  `json:"image_url"`
-			Tags      []string `json:"tags"`
-			Published *bool    `json:"published"`
+			Title     *string    `json:"title"`
+			Content   *string    `json:"content"`
+			ImageURL  *string    `json:"image_url"`
+			Tags      []string   `json:"tags"`
+			Published *bool      `json:"published"`
+			Status    *string    `json:"status"`
+			PublishAt *time.Time `json:"publish_at"`
 		}
 
 		if err := c.ShouldBindJSON(&data); err != nil {
@@ -333,16 +385,55 @@ func UpdatePost(client *mongo.Client) gin.HandlerFunc {
 			set["slug"] = GenerateUniqueSlug(*data.Title)
 		}
 		if data.Content != nil {
+			rendered, err := utils.RenderMarkdown(*data.Content)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Could not render content"})
+				return
+			}
 			set["content"] = *data.Content
+			for field, value := range rendered.Fields() {
+				set[field] = value
+			}
 		}
 		if data.ImageURL != nil {
 			set["image_url"] = *data.ImageURL
 		}
 		if data.Tags != nil {
-			set["tags"] = data.Tags
+			set["tags"] = utils.NormalizeTags(data.Tags)
+		}
+
+		update := bson.M{}
+		if len(set) > 0 {
+			// Saving edits supersedes whatever was autosaved.
+			update["$unset"] = bson.M{"autosave": ""}
+		}
+
+		// Clients that predate statuses flip published to publish or archive
+		// a post. A new publish_at alone reschedules a scheduled post.
+		status := ""
+		switch {
+		case data.Status != nil:
+			status = *data.Status
+		case data.Published != nil && *data.Published != post.Published:
+			status = utils.PostArchived
+			if *data.Published {
+				status = utils.PostPublished
+			}
+		case data.PublishAt != nil && post.Status == utils.PostScheduled:
+			status = utils.PostScheduled
 		}
-		if data.Published != nil {
-			set["published"] = *data.Published
+		if status != "" && (status != post.Status || status == utils.PostScheduled) {
+			publishAt := data.PublishAt
+			if publishAt == nil {
+				publishAt = post.PublishAt
+			}
+			if err := utils.SetPostStatus(&post, status, publishAt, time.Now()); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			for field, value := range utils.PostStatusFields(post) {
+				set[field] = value
+			}
 		}
 
 		if len(set) == 0 {
@@ -351,24 +442,30 @@ func UpdatePost(client *mongo.Client) gin.HandlerFunc {
 		}
 
 		set["updated_at"] = time.Now()
+		update["$set"] = set
 
-		_, err = collection.UpdateOne(
+		err = collection.FindOneAndUpdate(
 			context.Background(),
 			bson.M{"_id": postObjId},
-			bson.M{"$set": set},
-		)
+			update,
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&post)
 
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Update failed"})
 			return
 		}
 
+		if err := searcher.Index(context.Background(), post); err != nil {
+			log.Println("SEARCH INDEX FAILED:", err)
+		}
+
 		c.JSON(http.StatusOK, gin.H{"message": "Post updated"})
 	}
 }
 
 
-func DeletePost(client *mongo.Client) gin.HandlerFunc {
+func DeletePost(client *mongo.Client, searcher utils.Searcher) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		postId := c.Param("id")
 		userId, _ := c.Get("user_id")
@@ -387,11 +484,15 @@ func DeletePost(client *mongo.Client) gin.HandlerFunc {
 		}
 
 		col.DeleteOne(context.Background(), bson.M{"_id": postObjId})
+		searcher.Remove(context.Background(), postObjId)
 		c.JSON(http.StatusOK, gin.H{"message": "Post deleted"})
 	}
 }
 
 
+// GetArchivePosts lists the user's own posts, most recently edited first.
+// ?status= takes a comma separated list of statuses or "all"; without it
+// everything but published posts is listed, which is what the archive was.
 func GetArchivePosts(client *mongo.Client) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userId, exists := c.Get("user_id")
@@ -401,21 +502,82 @@ func GetArchivePosts(client *mongo.Client) gin.HandlerFunc {
 		}
 
 		authorId, _ := bson.ObjectIDFromHex(userId.(string))
+
+		filter := bson.M{"author_id": authorId}
+		switch raw := c.Query("status"); raw {
+		case "":
+			filter["status"] = bson.M{"$ne": utils.PostPublished}
+		case "all":
+		default:
+			statuses := []string{}
+			for _, status := range strings.Split(raw, ",") {
+				status = strings.TrimSpace(status)
+				if !utils.ValidPostStatus(status) {
+					c.JSON(http.StatusBadRequest, gin.H{"error": utils.ErrInvalidPostStatus.Error()})
+					return
+				}
+				statuses = append(statuses, status)
+			}
+			filter["status"] = bson.M{"$in": statuses}
+		}
+
+		page, ok := pageQuery(c, "updated_at", true)
+		if !ok {
+			return
+		}
+
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		cursor, err := database.OpenCollection("posts", client).Find(
-			ctx,
-			bson.M{"author_id": authorId, "published": false},
-		)
+		postCol := database.OpenCollection("posts", client)
+
+		cursor, err := postCol.Find(ctx, page.Filter(filter), page.FindOptions())
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch archive"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts"})
 			return
 		}
 
-		var posts []models.Post
-		cursor.All(ctx, &posts)
-		c.JSON(http.StatusOK, posts)
+		posts := []models.Post{}
+		if err := cursor.All(ctx, &posts); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse posts"})
+			return
+		}
+		posts, nextCursor := utils.Next(page, posts, func(post models.Post) (any, bson.ObjectID) {
+			return post.UpdatedAt, post.ID
+		})
+
+		// Per status totals for the tabs of the content list.
+		countCursor, err := postCol.Aggregate(ctx, mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{"author_id": authorId}}},
+			{{Key: "$group", Value: bson.M{"_id": "$status", "count": bson.M{"$sum": 1}}}},
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count posts"})
+			return
+		}
+
+		var groups []struct {
+			Status string `bson:"_id"`
+			Count  int    `bson:"count"`
+		}
+		if err := countCursor.All(ctx, &groups); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count posts"})
+			return
+		}
+
+		counts := gin.H{}
+		for _, status := range utils.PostStatuses {
+			counts[status] = 0
+		}
+		for _, group := range groups {
+			counts[group.Status] = group.Count
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"posts":       posts,
+			"counts":      counts,
+			"next_cursor": nextCursor,
+		})
 	}
 }
 
@@ -438,21 +600,20 @@ func GenerateUniqueSlug(title string) string {
 func GetMyPosts(client *mongo.Client) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
-		tokenStr, err := c.Cookie("access_token")
-		if err != nil {
+		userId, exists := c.Get("user_id")
+		if !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
 			return
 		}
 
-		claims, err := utils.VerifyToken(tokenStr)
+		userObjId, err := bson.ObjectIDFromHex(userId.(string))
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user id"})
 			return
 		}
 
-		userObjId, err := bson.ObjectIDFromHex(claims.UserID)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user id"})
+		page, ok := pageQuery(c, "published_at", true)
+		if !ok {
 			return
 		}
 
@@ -466,11 +627,7 @@ func GetMyPosts(client *mongo.Client) gin.HandlerFunc {
 			"published": true,
 		}
 
-		opts := options.Find().SetSort(bson.M{
-			"created_at": -1,
-		})
-
-		cursor, err := postCollection.Find(ctx, filter, opts)
+		cursor, err := postCollection.Find(ctx, page.Filter(filter), page.FindOptions())
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch posts"})
 			return
@@ -483,79 +640,150 @@ func GetMyPosts(client *mongo.Client) gin.HandlerFunc {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse posts"})
 			return
 		}
+		posts, nextCursor := utils.Next(page, posts, postPageKey)
 
 		c.JSON(http.StatusOK, gin.H{
-			"posts": posts,
+			"posts":       posts,
+			"next_cursor": nextCursor,
 		})
 	}
 }
 
 
-func SearchPost(client *mongo.Client) gin.HandlerFunc {
+type SearchHighlight struct {
+	Title   string `json:"title"`
+	Snippet string `json:"snippet"`
+}
+
+type SearchPostResponse struct {
+	PostResponse
+	Score     float64         `json:"score"`
+	Highlight SearchHighlight `json:"highlight"`
+}
+
+// parseSearchDate reads a YYYY-MM-DD day or an RFC 3339 time. A day used as
+// an upper bound includes the whole day.
+func parseSearchDate(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if day, err := time.Parse(time.DateOnly, value); err == nil {
+		if endOfDay {
+			day = day.AddDate(0, 0, 1)
+		}
+		return &day, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// SearchPost ranks published posts by relevance to ?q= over title, tags and
+// content. ?author= (id or handle), ?tag=, ?from= and ?to= narrow the
+// results. The older /posts/tags route passes the query as ?t=.
+func SearchPost(client *mongo.Client, searcher utils.Searcher) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
-		query := strings.TrimSpace(c.Query("t"))
-		if len(query) < 2 {
+		query := strings.TrimSpace(c.DefaultQuery("q", c.Query("t")))
+		if len(query) > utils.MaxSearchQueryLength {
 			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Search query missing",
+				"error": "Search query too long",
 			})
 			return
 		}
 
+		page, ok := pageQuery(c, "score", true)
+		if !ok {
+			return
+		}
+
+		search := utils.SearchQuery{
+			Text: query,
+			Tags: utils.NormalizeTags(strings.Split(c.Query("tag"), ",")),
+			Page: page,
+		}
+
+		var err error
+		if search.From, err = parseSearchDate(c.Query("from"), false); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be a date (YYYY-MM-DD) or RFC 3339 time"})
+			return
+		}
+		if search.Before, err = parseSearchDate(c.Query("to"), true); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be a date (YYYY-MM-DD) or RFC 3339 time"})
+			return
+		}
+
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		postCol := database.OpenCollection("posts", client)
-		userCol := database.OpenCollection("users", client)
+		if author := strings.TrimSpace(c.Query("author")); author != "" {
+			authorId, err := bson.ObjectIDFromHex(author)
+			if err != nil {
+				user, _, err := utils.ResolveHandle(ctx, client, utils.NormalizeHandle(author))
+				if errors.Is(err, mongo.ErrNoDocuments) {
+					c.JSON(http.StatusOK, gin.H{"posts": []SearchPostResponse{}, "next_cursor": nil})
+					return
+				}
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed"})
+					return
+				}
+				authorId = user.Id
+			}
+			search.AuthorID = &authorId
+		}
 
-		filter := bson.M{
-			"tags": bson.M{
-				"$regex":   query,
-				"$options": "i",
-			},
-			"published": true,
+		if len(query) < 2 && !search.HasFilters() {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Search query missing",
+			})
+			return
 		}
 
-		cursor, err := postCol.Find(ctx, filter)
+		hidden, err := utils.HiddenAuthorIDs(ctx, client)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Search failed",
 			})
 			return
 		}
-		defer cursor.Close(ctx)
+		search.ExcludeAuthors = hidden
 
-		var posts []models.Post
-		if err := cursor.All(ctx, &posts); err != nil {
+		result, err := searcher.Search(ctx, search)
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to parse posts",
+				"error": "Search failed",
 			})
 			return
 		}
 
-		var response []PostResponse
+		ids := []bson.ObjectID{}
+		for _, hit := range result.Hits {
+			ids = append(ids, hit.Post.AuthorID)
+		}
 
-		for _, post := range posts {
-			var user models.User
-			if err := userCol.FindOne(
-				ctx,
-				bson.M{"_id": post.AuthorID},
-			).Decode(&user); err != nil {
-				continue
-			}
+		authors, err := loadPostAuthors(ctx, client, ids)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to load authors",
+			})
+			return
+		}
 
-			response = append(response, PostResponse{
-				Post: post,
-				Author: PostAuthor{
-					ID:           user.Id,
-					Username:     user.UserName,
-					ProfileImage: user.ProfileImage,
-				},
+		response := []SearchPostResponse{}
+		for _, hit := range result.Hits {
+			response = append(response, SearchPostResponse{
+				PostResponse: PostResponse{Post: hit.Post, Author: authors.get(hit.Post.AuthorID)},
+				Score:        hit.Score,
+				Highlight:    SearchHighlight{Title: hit.Title, Snippet: hit.Snippet},
 			})
 		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"posts": response,
+			"posts":       response,
+			"next_cursor": result.NextCursor,
 		})
 	}
 }
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"context"
+	"slices"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const deletedAuthorName = "deleted user"
+
+// postAuthors maps author ids to the public author shown next to a post.
+type postAuthors map[bson.ObjectID]PostAuthor
+
+// loadPostAuthors fetches all authors of a page of posts with one query.
+func loadPostAuthors(ctx context.Context, client *mongo.Client, ids []bson.ObjectID) (postAuthors, error) {
+	authors := postAuthors{}
+
+	unique := []bson.ObjectID{}
+	for _, id := range ids {
+		if !slices.Contains(unique, id) {
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) == 0 {
+		return authors, nil
+	}
+
+	cursor, err := database.OpenCollection("users", client).Find(
+		ctx,
+		bson.M{
+			"_id":                   bson.M{"$in": unique},
+			"deletion_requested_at": bson.M{"$exists": false},
+		},
+		options.Find().SetProjection(bson.M{"name": 1, "handle": 1, "profile_image": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	users := []models.User{}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		authors[user.Id] = PostAuthor{
+			ID:           user.Id,
+			Handle:       user.Handle,
+			Username:     user.UserName,
+			ProfileImage: user.ProfileImage,
+		}
+	}
+	return authors, nil
+}
+
+// get returns the author of a post. Posts outliving their author's account
+// are still listed, under a placeholder.
+func (a postAuthors) get(id bson.ObjectID) PostAuthor {
+	if author, ok := a[id]; ok {
+		return author
+	}
+	return PostAuthor{ID: id, Username: deletedAuthorName, Deleted: true}
+}
+
+// hydratePosts attaches authors to posts, keeping their order.
+func hydratePosts(ctx context.Context, client *mongo.Client, posts []models.Post) ([]PostResponse, error) {
+	ids := make([]bson.ObjectID, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.AuthorID)
+	}
+
+	authors, err := loadPostAuthors(ctx, client, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]PostResponse, 0, len(posts))
+	for _, post := range posts {
+		response = append(response, PostResponse{Post: post, Author: authors.get(post.AuthorID)})
+	}
+	return response, nil
+}
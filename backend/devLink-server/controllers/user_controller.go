@@ -2,13 +2,17 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/ayushmehta03/devLink-backend/database"
 	"github.com/ayushmehta03/devLink-backend/models"
+	"github.com/ayushmehta03/devLink-backend/utils"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
@@ -41,38 +45,117 @@ func GetUserProfile(client *mongo.Client )gin.HandlerFunc{
 
 
 		userCollection:=database.OpenCollection("users",client)
-		postCollection:=database.OpenCollection("posts",client)
 
 		var user models.User
 
 
-		err=userCollection.FindOne(ctx,bson.M{"_id":userObjId}).Decode(&user)
+		err=userCollection.FindOne(ctx,bson.M{"_id":userObjId,"deletion_requested_at":bson.M{"$exists":false}}).Decode(&user)
 
 		if err!=nil{
 		c.JSON(http.StatusNotFound,gin.H{"error":"User not found"})
 		return 
 		}
 
-		filter:=bson.M{
-			"author_id":user.Id,
-			"published":true,
+		writeUserProfile(ctx,c,client,user)
+	}
+}
+
+// writeUserProfile answers with the public part of a profile and the user's
+// published posts.
+func writeUserProfile(ctx context.Context, c *gin.Context, client *mongo.Client, user models.User) {
+	cursor, err := database.OpenCollection("posts", client).Find(ctx, bson.M{
+		"author_id": user.Id,
+		"published": true,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts"})
+		return
+	}
+
+	posts := []models.Post{}
+	cursor.All(ctx, &posts)
+
+	// Pinned posts keep the order the user chose; ones unpublished since
+	// pinning are left out.
+	pinned := []models.Post{}
+	for _, id := range user.PinnedPosts {
+		for _, post := range posts {
+			if post.ID == id {
+				pinned = append(pinned, post)
+				break
+			}
 		}
+	}
 
-		cursor,_:=postCollection.Find(ctx,filter)
+	skills := user.Skills
+	if skills == nil {
+		skills = []models.Skill{}
+	}
 
-		 posts:=[]models.Post{}
+	c.JSON(http.StatusOK, gin.H{
+		"user": gin.H{
+			"id":                  user.Id.Hex(),
+			"handle":              user.Handle,
+			"name":                user.UserName,
+			"bio":                 user.Bio,
+			"profile_image":       user.ProfileImage,
+			"last_seen":           user.LastSeen,
+			"skills":              skills,
+			"links":               user.Links,
+			"location":            user.Location,
+			"timezone":            user.Timezone,
+			"open_to_collaborate": user.OpenToCollaborate,
+			"open_to_hire":        user.OpenToHire,
+		},
+		"pinned_posts": pinned,
+		"posts":        posts,
+	})
+}
 
-		cursor.All(ctx,&posts)
+// GetUserByHandle serves public profiles by handle. Handles released by a
+// rename redirect to the owner's current handle.
+func GetUserByHandle(client *mongo.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		handle := utils.NormalizeHandle(c.Param("handle"))
+		if handle == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Handle is required"})
+			return
+		}
 
-c.JSON(http.StatusOK, gin.H{
-			"user": gin.H{
-				"name": user.UserName,
-				"bio":  user.Bio,
-				"profile_image":user.ProfileImage,
-				"last_seen":user.LastSeen,
-			},
-			"posts": posts,
-		})	}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		user, moved, err := utils.ResolveHandle(ctx, client, handle)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+			return
+		}
+
+		if moved {
+			c.Redirect(http.StatusMovedPermanently, "/api/u/"+user.Handle)
+			return
+		}
+
+		writeUserProfile(ctx, c, client, user)
+	}
+}
+
+// respondHandleError maps handle validation failures to API errors.
+func respondHandleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, utils.ErrHandleInvalid), errors.Is(err, utils.ErrHandleReserved):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "HANDLE_INVALID"})
+	case errors.Is(err, utils.ErrHandleTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "HANDLE_TAKEN"})
+	case errors.Is(err, utils.ErrHandleTooSoon):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error(), "code": "HANDLE_CHANGE_TOO_SOON"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update handle"})
+	}
 }
 
 
@@ -107,9 +190,18 @@ func UpdateProfile(client *mongo.Client)gin.HandlerFunc{
 
 		var data struct{
 			Username *string `json:"username"`
+			Handle *string `json:"handle"`
 			Bio *string `json:"bio"`
 			ProfileImage *string `json:"profile_image"`
-
+			Locale *string `json:"locale"`
+
+			Skills            *[]models.Skill      `json:"skills"`
+			Links             *models.ProfileLinks `json:"links"`
+			Location          *string              `json:"location"`
+			Timezone          *string              `json:"timezone"`
+			OpenToCollaborate *bool                `json:"open_to_collaborate"`
+			OpenToHire        *bool                `json:"open_to_hire"`
+			PinnedPosts       *[]string            `json:"pinned_posts"`
 		}
 
 		if err:=c.ShouldBindJSON(&data);err!=nil{
@@ -120,10 +212,30 @@ func UpdateProfile(client *mongo.Client)gin.HandlerFunc{
 		set:=bson.M{}
 
 		if data.Username!=nil{
-			set["username"]=*data.Username
+			name := strings.TrimSpace(*data.Username)
+			if len(name) < 5 || len(name) > 22 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Name must be between 5 and 22 characters"})
+				return
+			}
+			set["name"]=name
+		}
+
+		var handle string
+		if data.Handle!=nil{
+			handle = utils.NormalizeHandle(*data.Handle)
+			if handle == user.Handle {
+				handle = ""
+			} else if err := utils.CheckHandleAvailable(ctx, client, handle, userId); err != nil {
+				respondHandleError(c, err)
+				return
+			}
 		}
 
 		if data.Bio!=nil{
+			if utf8.RuneCountInString(*data.Bio) > utils.MaxBioLength {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Bio must be at most 160 characters"})
+				return
+			}
 			set["bio"]=*data.Bio
 		}
 
@@ -131,22 +243,92 @@ func UpdateProfile(client *mongo.Client)gin.HandlerFunc{
 			set["profile_image"]=*data.ProfileImage
 		}
 
-		if len(set) == 0 {
+		if data.Locale!=nil{
+			set["locale"]=utils.ResolveLocale(*data.Locale)
+		}
+
+		if data.Skills != nil {
+			skills, err := utils.NormalizeSkills(*data.Skills)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			set["skills"] = skills
+		}
+
+		if data.Links != nil {
+			links, err := utils.NormalizeProfileLinks(*data.Links)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			set["links"] = links
+		}
+
+		if data.Location != nil {
+			location := strings.TrimSpace(*data.Location)
+			if len(location) > utils.MaxLocationLength {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Location must be at most 100 characters"})
+				return
+			}
+			set["location"] = location
+		}
+
+		if data.Timezone != nil {
+			timezone := strings.TrimSpace(*data.Timezone)
+			if timezone != "" && !utils.ValidTimezone(timezone) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Timezone must be an IANA name such as Europe/Berlin"})
+				return
+			}
+			set["timezone"] = timezone
+		}
+
+		if data.OpenToCollaborate != nil {
+			set["open_to_collaborate"] = *data.OpenToCollaborate
+		}
+
+		if data.OpenToHire != nil {
+			set["open_to_hire"] = *data.OpenToHire
+		}
+
+		if data.PinnedPosts != nil {
+			pinned, err := utils.ParsePinnedPosts(ctx, client, userId, *data.PinnedPosts)
+			if errors.Is(err, utils.ErrInvalidPinnedPost) || errors.Is(err, utils.ErrTooManyPinnedPosts) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Update failed"})
+				return
+			}
+			set["pinned_posts"] = pinned
+		}
+
+		if len(set) == 0 && handle == "" {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Nothing to update"})
 			return
 		}
 
-		set["updated_at"] = time.Now()
+		if handle != "" {
+			if err := utils.ChangeHandle(ctx, client, userId, user.Handle, handle); err != nil {
+				respondHandleError(c, err)
+				return
+			}
+		}
 
-		_, err = userCollection.UpdateOne(
-			context.Background(),
-			bson.M{"_id": userId},
-			bson.M{"$set": set},
-		)
+		if len(set) > 0 {
+			set["updated_at"] = time.Now()
 
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Update failed"})
-			return
+			_, err = userCollection.UpdateOne(
+				ctx,
+				bson.M{"_id": userId},
+				bson.M{"$set": set},
+			)
+
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Update failed"})
+				return
+			}
 		}
 
 		c.JSON(http.StatusOK, gin.H{"message": "Profile updated"})
@@ -167,26 +349,63 @@ func SearchUsers(client *mongo.Client) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		query := strings.TrimSpace(c.Query("q"))
-		if len(query) < 2 {
+		skills := []string{}
+		for _, skill := range strings.Split(c.Query("skill"), ",") {
+			if skill = strings.Join(strings.Fields(strings.ToLower(skill)), " "); skill != "" {
+				skills = append(skills, skill)
+			}
+		}
+		open := strings.ToLower(strings.TrimSpace(c.Query("open")))
+
+		if len(query) < 2 && len(skills) == 0 && open == "" {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": "Query missing",
 			})
 			return
 		}
 
+		page, ok := pageQuery(c, "name", false)
+		if !ok {
+			return
+		}
+
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
 		userCollection := database.OpenCollection("users", client)
 
 		filter := bson.M{
-			"name": bson.M{
-				"$regex":   query,
-				"$options": "i",
-			},
+			"deletion_requested_at": bson.M{"$exists": false},
 		}
 
-		cursor, err := userCollection.Find(ctx, filter)
+		if query != "" {
+			filter["$or"] = []bson.M{
+				{"name": bson.M{"$regex": regexp.QuoteMeta(query), "$options": "i"}},
+				{"handle": bson.M{"$regex": "^" + regexp.QuoteMeta(utils.NormalizeHandle(query))}},
+			}
+		}
+
+		if len(skills) > 0 {
+			filter["skills.name"] = bson.M{"$all": skills}
+		}
+
+		switch open {
+		case "":
+		case "true", "1", "any":
+			filter["$and"] = []bson.M{{"$or": []bson.M{
+				{"open_to_collaborate": true},
+				{"open_to_hire": true},
+			}}}
+		case "collaborate":
+			filter["open_to_collaborate"] = true
+		case "hire":
+			filter["open_to_hire"] = true
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "open must be true, collaborate or hire"})
+			return
+		}
+
+		cursor, err := userCollection.Find(ctx, page.Filter(filter), page.FindOptions())
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Search failed",
@@ -195,31 +414,48 @@ func SearchUsers(client *mongo.Client) gin.HandlerFunc {
 		}
 		defer cursor.Close(ctx)
 
-		type UserResponse struct {
-			ID           string `json:"id"`
-			Username     string `json:"username"`
-			Bio          string `json:"bio,omitempty"`
-			ProfileImage string `json:"profile_image,omitempty"`
+		found := []models.User{}
+		if err := cursor.All(ctx, &found); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Search failed",
+			})
+			return
 		}
+		found, nextCursor := utils.Next(page, found, func(user models.User) (any, bson.ObjectID) {
+			return user.UserName, user.Id
+		})
 
-		var users []UserResponse
+		type UserResponse struct {
+			ID                string         `json:"id"`
+			Username          string         `json:"username"`
+			Handle            string         `json:"handle,omitempty"`
+			Bio               string         `json:"bio,omitempty"`
+			ProfileImage      string         `json:"profile_image,omitempty"`
+			Skills            []models.Skill `json:"skills,omitempty"`
+			Location          string         `json:"location,omitempty"`
+			OpenToCollaborate bool           `json:"open_to_collaborate"`
+			OpenToHire        bool           `json:"open_to_hire"`
+		}
 
-		for cursor.Next(ctx) {
-			var user models.User
-			if err := cursor.Decode(&user); err != nil {
-				continue
-			}
+		users := []UserResponse{}
 
+		for _, user := range found {
 			users = append(users, UserResponse{
-				ID:           user.Id.Hex(),     
-				Username:     user.UserName,
-				Bio:          user.Bio,
-				ProfileImage: user.ProfileImage,
+				ID:                user.Id.Hex(),     
+				Username:          user.UserName,
+				Handle:            user.Handle,
+				Bio:               user.Bio,
+				ProfileImage:      user.ProfileImage,
+				Skills:            user.Skills,
+				Location:          user.Location,
+				OpenToCollaborate: user.OpenToCollaborate,
+				OpenToHire:        user.OpenToHire,
 			})
 		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"users": users,
+			"users":       users,
+			"next_cursor": nextCursor,
 		})
 	}
 }
@@ -265,18 +501,33 @@ func GetUserProfileStats(client *mongo.Client) gin.HandlerFunc {
 		var result []bson.M
 		cursor.All(ctx, &result)
 
-		if len(result) == 0 {
-			c.JSON(http.StatusOK, gin.H{
-				"total_posts": 0,
-				"total_views": 0,
-			})
+		followers, following, err := utils.FollowCounts(ctx, client, userObjId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch stats"})
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{
-			"total_posts": result[0]["totalPosts"],
-			"total_views": result[0]["totalViews"],
-		})
+		stats := gin.H{
+			"total_posts":     0,
+			"total_views":     0,
+			"followers_count": followers,
+			"following_count": following,
+		}
+
+		if len(result) > 0 {
+			stats["total_posts"] = result[0]["totalPosts"]
+			stats["total_views"] = result[0]["totalViews"]
+		}
+
+		if viewerId, err := bson.ObjectIDFromHex(c.GetString("user_id")); err == nil && viewerId != userObjId {
+			if rel, err := utils.GetFollowRelation(ctx, client, viewerId, userObjId); err == nil {
+				stats["is_following"] = rel.Following
+				stats["follows_you"] = rel.FollowedBy
+				stats["mutual"] = rel.Mutual()
+			}
+		}
+
+		c.JSON(http.StatusOK, stats)
 	}
 }
 
@@ -327,6 +578,7 @@ func GetSuggestedUsers(client *mongo.Client) gin.HandlerFunc {
 			ID           string `json:"id"`              
 			UserId       string `json:"user_id"`         
 			UserName     string `json:"username"`
+			Handle       string `json:"handle,omitempty"`
 			ProfileImage string `json:"profile_image,omitempty"`
 		}
 
@@ -343,6 +595,7 @@ func GetSuggestedUsers(client *mongo.Client) gin.HandlerFunc {
 				ID:           u.Id.Hex(),   
 				UserId:       u.UserId,    
 				UserName:     u.UserName,
+				Handle:       u.Handle,
 				ProfileImage: u.ProfileImage,
 			})
 		}
@@ -3,13 +3,13 @@ package controllers
 import (
 	"context"
 	"net/http"
-	"os"
+	"sync"
 	"time"
 
 	"github.com/ayushmehta03/devLink-backend/database"
 	"github.com/ayushmehta03/devLink-backend/models"
+	"github.com/ayushmehta03/devLink-backend/utils"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/gorilla/websocket"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
@@ -28,7 +28,12 @@ type ClientInfo struct {
 
 var roomClients = make(map[string]map[*websocket.Conn]string)
 
+var roomClientsMu sync.Mutex
+
 func broadcast(roomKey string, payload gin.H) {
+	roomClientsMu.Lock()
+	defer roomClientsMu.Unlock()
+
 	for conn := range roomClients[roomKey] {
 		if err := conn.WriteJSON(payload); err != nil {
 			conn.Close()
@@ -37,6 +42,73 @@ func broadcast(roomKey string, payload gin.H) {
 	}
 }
 
+// inRoom reports whether the user has the room open right now.
+func inRoom(roomKey, userIDHex string) bool {
+	roomClientsMu.Lock()
+	defer roomClientsMu.Unlock()
+
+	for _, uid := range roomClients[roomKey] {
+		if uid == userIDHex {
+			return true
+		}
+	}
+	return false
+}
+
+// deliverMessage stores a chat message, bumps its room in the room list and
+// pushes it to everyone who has the room open.
+func deliverMessage(ctx context.Context, client *mongo.Client, roomID, senderID bson.ObjectID, content string) (models.Message, error) {
+	message := models.Message{
+		ID:        bson.NewObjectID(),
+		RoomID:    roomID,
+		SenderID:  senderID,
+		Content:   content,
+		CreatedAt: time.Now(),
+	}
+	if _, err := database.OpenCollection("messages", client).InsertOne(ctx, message); err != nil {
+		return message, err
+	}
+
+	database.OpenCollection("chat_rooms", client).UpdateOne(ctx, bson.M{"_id": roomID}, bson.M{
+		"$set": bson.M{"last_message_at": message.CreatedAt},
+	})
+
+	broadcast(roomID.Hex(), gin.H{
+		"type":       "message",
+		"id":         message.ID.Hex(),
+		"room_id":    roomID.Hex(),
+		"sender_id":  senderID.Hex(),
+		"content":    message.Content,
+		"created_at": message.CreatedAt,
+	})
+	return message, nil
+}
+
+// DisconnectUser closes every live chat socket of a user, for instance when
+// the account is suspended. It returns the number of sockets closed.
+func DisconnectUser(userIDHex, reason string) int {
+	roomClientsMu.Lock()
+	var conns []*websocket.Conn
+	for _, clients := range roomClients {
+		for conn, uid := range clients {
+			if uid == userIDHex {
+				conns = append(conns, conn)
+			}
+		}
+	}
+	roomClientsMu.Unlock()
+
+	for _, conn := range conns {
+		conn.WriteControl(
+			websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason),
+			time.Now().Add(time.Second),
+		)
+		conn.Close()
+	}
+	return len(conns)
+}
+
 func ChatWebSocket(client *mongo.Client) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
@@ -45,21 +117,18 @@ func ChatWebSocket(client *mongo.Client) gin.HandlerFunc {
 		}
 
 		tokenString := c.Query("token")
-		secret := os.Getenv("JWT_SECRET")
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return []byte(secret), nil
-		})
-		if err != nil || !token.Valid {
+		claims, err := utils.VerifyPurposeToken(tokenString, "ws")
+		if err != nil {
 			conn.Close()
 			return
 		}
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok || claims["type"] != "ws" {
+		userIDHex := claims.UserID
+		userID, _ := bson.ObjectIDFromHex(userIDHex)
+
+		if restriction, err := utils.UserRestriction(context.Background(), client, userID); err != nil || restriction != nil {
 			conn.Close()
 			return
 		}
-		userIDHex := claims["user_id"].(string)
-		userID, _ := bson.ObjectIDFromHex(userIDHex)
 
 		roomIDParam := c.Param("room_id")
 		roomID, _ := bson.ObjectIDFromHex(roomIDParam)
@@ -72,12 +141,19 @@ func ChatWebSocket(client *mongo.Client) gin.HandlerFunc {
 			return
 		}
 
+		roomClientsMu.Lock()
 		if roomClients[roomKey] == nil {
 			roomClients[roomKey] = make(map[*websocket.Conn]string)
 		}
 		roomClients[roomKey][conn] = userIDHex
 
+		var online []string
 		for _, existingUserID := range roomClients[roomKey] {
+			online = append(online, existingUserID)
+		}
+		roomClientsMu.Unlock()
+
+		for _, existingUserID := range online {
 			if existingUserID != userIDHex {
 				conn.WriteJSON(gin.H{
 					"type":    "user_online",
@@ -108,7 +184,9 @@ func ChatWebSocket(client *mongo.Client) gin.HandlerFunc {
 		}()
 
 		defer func() {
+			roomClientsMu.Lock()
 			delete(roomClients[roomKey], conn)
+			roomClientsMu.Unlock()
 			now := time.Now()
 			usersCol := database.OpenCollection("users", client)
 			usersCol.UpdateOne(context.Background(), bson.M{"_id": userID}, bson.M{"$set": bson.M{"last_seen": now}})
@@ -142,23 +220,7 @@ func ChatWebSocket(client *mongo.Client) gin.HandlerFunc {
 				if payload.Content == "" {
 					continue
 				}
-				msgCol := database.OpenCollection("messages", client)
-				message := models.Message{
-					ID:        bson.NewObjectID(),
-					RoomID:    roomID,
-					SenderID:  userID,
-					Content:   payload.Content,
-					CreatedAt: time.Now(),
-				}
-				msgCol.InsertOne(context.Background(), message)
-				broadcast(roomKey, gin.H{
-					"type":       "message",
-					"id":         message.ID.Hex(),
-					"room_id":    roomKey,
-					"sender_id":  userIDHex,
-					"content":    message.Content,
-					"created_at": message.CreatedAt,
-				})
+				deliverMessage(context.Background(), client, roomID, userID, payload.Content)
 			}
 		}
 	}
@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// StartAccountPurge removes accounts whose deletion grace period has passed
+// once per interval until ctx is cancelled.
+func StartAccountPurge(ctx context.Context, client *mongo.Client, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := PurgeDeletedAccounts(ctx, client, time.Now()); err != nil {
+					log.Println("ACCOUNT PURGE FAILED:", err)
+				}
+			}
+		}
+	}()
+}
+
+func PurgeDeletedAccounts(ctx context.Context, client *mongo.Client, now time.Time) error {
+	cutoff := now.Add(-utils.AccountDeletionGrace)
+
+	cursor, err := database.OpenCollection("users", client).Find(
+		ctx,
+		bson.M{"deletion_requested_at": bson.M{"$lte": cutoff}},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var user struct {
+			ID bson.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&user); err != nil {
+			continue
+		}
+
+		if err := utils.PurgeAccount(ctx, client, user.ID, cutoff); err != nil {
+			if errors.Is(err, utils.ErrPurgeCancelled) {
+				log.Println("ACCOUNT PURGE CANCELLED:", user.ID.Hex())
+				continue
+			}
+			log.Println("ACCOUNT PURGE FAILED FOR", user.ID.Hex()+":", err)
+			continue
+		}
+		log.Println("ACCOUNT PURGED:", user.ID.Hex())
+	}
+	return cursor.Err()
+}
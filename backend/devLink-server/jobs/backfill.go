@@ -0,0 +1,43 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Backfills bring documents written by older versions up to date. Each is
+// safe to run again and to run on several servers at once.
+var backfills = []struct {
+	Name string
+	Run  func(ctx context.Context, client *mongo.Client) error
+}{
+	{"handle", utils.BackfillHandles},
+	{"post rendering", utils.BackfillRenderedPosts},
+	{"post status", utils.BackfillPostStatus},
+	{"post tags", utils.BackfillPostTags},
+	{"chat room activity", utils.BackfillRoomActivity},
+}
+
+// StartBackfills runs every backfill once in the background, each with its
+// own timeout, so that a large collection neither delays startup nor eats
+// into the deadline of the others.
+func StartBackfills(ctx context.Context, client *mongo.Client, timeout time.Duration) {
+	go func() {
+		for _, backfill := range backfills {
+			runCtx, cancel := context.WithTimeout(ctx, timeout)
+			err := backfill.Run(runCtx, client)
+			cancel()
+
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				log.Println(backfill.Name, "backfill failed:", err)
+			}
+		}
+	}()
+}
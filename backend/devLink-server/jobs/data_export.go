@@ -0,0 +1,549 @@
+package jobs
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	// A running export older than this is assumed to have died with its
+	// process and is picked up again.
+	exportStaleAfter  = 30 * time.Minute
+	exportMaxAttempts = 3
+	exportTimeout     = 20 * time.Minute
+)
+
+// StartDataExports builds queued personal data archives and removes expired
+// ones once per interval until ctx is cancelled.
+func StartDataExports(ctx context.Context, client *mongo.Client, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := RunDataExports(ctx, client, time.Now()); err != nil {
+					log.Println("DATA EXPORT FAILED:", err)
+				}
+			}
+		}
+	}()
+}
+
+func RunDataExports(ctx context.Context, client *mongo.Client, now time.Time) error {
+	if err := utils.DeleteDataExports(ctx, client, bson.M{"expires_at": bson.M{"$lte": now}}); err != nil {
+		log.Println("EXPIRED DATA EXPORT CLEANUP FAILED:", err)
+	}
+
+	exportCollection := database.OpenCollection("data_exports", client)
+
+	for ctx.Err() == nil {
+		var export models.DataExport
+		err := exportCollection.FindOneAndUpdate(
+			ctx,
+			bson.M{"$or": []bson.M{
+				{"status": utils.ExportPending},
+				{"status": utils.ExportRunning, "started_at": bson.M{"$lte": time.Now().Add(-exportStaleAfter)}},
+			}},
+			bson.M{
+				"$set": bson.M{"status": utils.ExportRunning, "started_at": time.Now()},
+				"$inc": bson.M{"attempts": 1},
+			},
+			options.FindOneAndUpdate().
+				SetSort(bson.D{{Key: "created_at", Value: 1}}).
+				SetReturnDocument(options.After),
+		).Decode(&export)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		processDataExport(ctx, client, export)
+	}
+	return ctx.Err()
+}
+
+func processDataExport(ctx context.Context, client *mongo.Client, export models.DataExport) {
+	exportCollection := database.OpenCollection("data_exports", client)
+
+	if export.Attempts > exportMaxAttempts {
+		failDataExport(ctx, exportCollection, export.ID, "Export could not be generated")
+		return
+	}
+
+	buildCtx, cancel := context.WithTimeout(ctx, exportTimeout)
+	defer cancel()
+
+	fileName := fmt.Sprintf("devlink-export-%s.zip", export.CreatedAt.UTC().Format("2006-01-02"))
+
+	fileID, size, err := writeDataExport(buildCtx, client, export.UserID, fileName)
+	if err != nil {
+		log.Println("DATA EXPORT", export.ID.Hex(), "ATTEMPT", export.Attempts, "FAILED:", err)
+
+		if export.Attempts >= exportMaxAttempts {
+			failDataExport(ctx, exportCollection, export.ID, "Export could not be generated")
+			return
+		}
+
+		exportCollection.UpdateOne(ctx, bson.M{"_id": export.ID}, bson.M{
+			"$set": bson.M{"status": utils.ExportPending},
+		})
+		return
+	}
+
+	now := time.Now()
+	if _, err := exportCollection.UpdateOne(ctx, bson.M{"_id": export.ID}, bson.M{
+		"$set": bson.M{
+			"status":       utils.ExportReady,
+			"file_id":      fileID,
+			"file_name":    fileName,
+			"size":         size,
+			"completed_at": now,
+			"expires_at":   now.Add(utils.DataExportTTL),
+		},
+		"$unset": bson.M{"error": ""},
+	}); err != nil {
+		log.Println("DATA EXPORT", export.ID.Hex(), "STATUS UPDATE FAILED:", err)
+		utils.ExportBucket(client).Delete(ctx, fileID)
+	}
+}
+
+func failDataExport(ctx context.Context, exportCollection *mongo.Collection, id bson.ObjectID, reason string) {
+	now := time.Now()
+	exportCollection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{
+			"status":       utils.ExportFailed,
+			"error":        reason,
+			"completed_at": now,
+			"expires_at":   now.Add(utils.DataExportTTL),
+		},
+	})
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// writeDataExport streams the user's archive into GridFS and returns the
+// stored file's id and size.
+func writeDataExport(ctx context.Context, client *mongo.Client, userID bson.ObjectID, fileName string) (bson.ObjectID, int64, error) {
+	upload, err := utils.ExportBucket(client).OpenUploadStream(
+		ctx,
+		fileName,
+		options.GridFSUpload().SetMetadata(bson.M{"user_id": userID}),
+	)
+	if err != nil {
+		return bson.ObjectID{}, 0, err
+	}
+
+	counter := &countingWriter{w: upload}
+	archive := zip.NewWriter(counter)
+
+	if err := writeExportEntries(ctx, client, archive, userID); err != nil {
+		upload.Abort()
+		return bson.ObjectID{}, 0, err
+	}
+
+	if err := archive.Close(); err != nil {
+		upload.Abort()
+		return bson.ObjectID{}, 0, err
+	}
+
+	if err := upload.Close(); err != nil {
+		return bson.ObjectID{}, 0, err
+	}
+
+	return upload.FileID.(bson.ObjectID), counter.n, nil
+}
+
+const exportReadme = `devLink personal data export
+
+profile.json         your account profile
+posts/posts.json     every post you wrote, as JSON
+posts/*.md           the same posts as Markdown with YAML front matter
+chat_requests.json   chat requests you sent and received
+follows.json         users you follow and users following you
+messages/*.json      the full history of every chat room you are part of
+
+All times are in RFC 3339 format.
+`
+
+type exportProfile struct {
+	ID                string              `json:"id"`
+	Handle            string              `json:"handle,omitempty"`
+	Name              string              `json:"name"`
+	Email             string              `json:"email"`
+	Bio               string              `json:"bio,omitempty"`
+	ProfileImage      string              `json:"profile_image,omitempty"`
+	Skills            []models.Skill      `json:"skills,omitempty"`
+	Links             models.ProfileLinks `json:"links"`
+	Location          string              `json:"location,omitempty"`
+	Timezone          string              `json:"timezone,omitempty"`
+	OpenToCollaborate bool                `json:"open_to_collaborate"`
+	OpenToHire        bool                `json:"open_to_hire"`
+	FollowedTags      []string            `json:"followed_tags,omitempty"`
+	Role              string              `json:"role"`
+	Locale            string              `json:"locale,omitempty"`
+	IsVerified        bool                `json:"is_verified"`
+	TOTPEnabled       bool                `json:"two_factor_enabled"`
+	CreatedAt         time.Time           `json:"created_at"`
+	UpdatedAt         time.Time           `json:"updated_at"`
+	LastSeen          *time.Time          `json:"last_seen,omitempty"`
+}
+
+type exportParticipant struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func writeExportEntries(ctx context.Context, client *mongo.Client, archive *zip.Writer, userID bson.ObjectID) error {
+	if err := writeZipFile(archive, "README.txt", []byte(exportReadme)); err != nil {
+		return err
+	}
+
+	var user models.User
+	if err := database.OpenCollection("users", client).FindOne(ctx, bson.M{"_id": userID}).Decode(&user); err != nil {
+		return err
+	}
+
+	if err := writeZipJSON(archive, "profile.json", exportProfile{
+		ID:                user.Id.Hex(),
+		Handle:            user.Handle,
+		Name:              user.UserName,
+		Email:             user.Email,
+		Bio:               user.Bio,
+		ProfileImage:      user.ProfileImage,
+		Skills:            user.Skills,
+		Links:             user.Links,
+		Location:          user.Location,
+		Timezone:          user.Timezone,
+		OpenToCollaborate: user.OpenToCollaborate,
+		OpenToHire:        user.OpenToHire,
+		FollowedTags:      user.FollowedTags,
+		Role:              user.Role,
+		Locale:            user.Locale,
+		IsVerified:        user.IsVerified,
+		TOTPEnabled:       user.TOTPEnabled,
+		CreatedAt:         user.CreatedAt.UTC(),
+		UpdatedAt:         user.UpdatedAt.UTC(),
+		LastSeen:          user.LastSeen,
+	}); err != nil {
+		return err
+	}
+
+	if err := writeExportPosts(ctx, client, archive, userID); err != nil {
+		return err
+	}
+
+	if err := writeExportChatRequests(ctx, client, archive, userID); err != nil {
+		return err
+	}
+
+	if err := writeExportFollows(ctx, client, archive, userID); err != nil {
+		return err
+	}
+
+	return writeExportMessages(ctx, client, archive, userID)
+}
+
+func writeExportPosts(ctx context.Context, client *mongo.Client, archive *zip.Writer, userID bson.ObjectID) error {
+	cursor, err := database.OpenCollection("posts", client).Find(
+		ctx,
+		bson.M{"author_id": userID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}),
+	)
+	if err != nil {
+		return err
+	}
+
+	posts := []models.Post{}
+	if err := cursor.All(ctx, &posts); err != nil {
+		return err
+	}
+
+	if err := writeZipJSON(archive, "posts/posts.json", posts); err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		if err := writeZipFile(archive, "posts/"+postFileName(post)+".md", postMarkdown(post)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postFileName keeps slugs that are safe as file names and falls back to the
+// post id otherwise.
+func postFileName(post models.Post) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		}
+		return '-'
+	}, post.Slug)
+
+	if strings.Trim(name, "-") == "" {
+		return post.ID.Hex()
+	}
+	return name
+}
+
+func postMarkdown(post models.Post) []byte {
+	var b strings.Builder
+
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "id: %s\n", post.ID.Hex())
+	fmt.Fprintf(&b, "title: %s\n", strconv.Quote(post.Title))
+	if post.Slug != "" {
+		fmt.Fprintf(&b, "slug: %s\n", strconv.Quote(post.Slug))
+	}
+	fmt.Fprintf(&b, "published: %t\n", post.Published)
+	if post.Status != "" {
+		fmt.Fprintf(&b, "status: %s\n", post.Status)
+	}
+	if post.PublishAt != nil {
+		fmt.Fprintf(&b, "publish_at: %s\n", post.PublishAt.UTC().Format(time.RFC3339))
+	}
+	if len(post.Tags) > 0 {
+		b.WriteString("tags:\n")
+		for _, tag := range post.Tags {
+			fmt.Fprintf(&b, "  - %s\n", strconv.Quote(tag))
+		}
+	}
+	if post.ImageURL != "" {
+		fmt.Fprintf(&b, "image_url: %s\n", strconv.Quote(post.ImageURL))
+	}
+	fmt.Fprintf(&b, "view_count: %d\n", post.ViewCount)
+	fmt.Fprintf(&b, "created_at: %s\n", post.CreatedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "updated_at: %s\n", post.UpdatedAt.UTC().Format(time.RFC3339))
+	b.WriteString("---\n\n")
+
+	b.WriteString(post.Content)
+	if !strings.HasSuffix(post.Content, "\n") {
+		b.WriteString("\n")
+	}
+	return []byte(b.String())
+}
+
+func writeExportChatRequests(ctx context.Context, client *mongo.Client, archive *zip.Writer, userID bson.ObjectID) error {
+	requestCollection := database.OpenCollection("chat_requests", client)
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	requests := map[string][]models.ChatRequest{}
+	for key, field := range map[string]string{"sent": "sender_id", "received": "receiver_id"} {
+		cursor, err := requestCollection.Find(ctx, bson.M{field: userID}, opts)
+		if err != nil {
+			return err
+		}
+
+		list := []models.ChatRequest{}
+		if err := cursor.All(ctx, &list); err != nil {
+			return err
+		}
+		requests[key] = list
+	}
+
+	return writeZipJSON(archive, "chat_requests.json", requests)
+}
+
+type exportFollow struct {
+	UserID     string    `json:"user_id"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+func writeExportFollows(ctx context.Context, client *mongo.Client, archive *zip.Writer, userID bson.ObjectID) error {
+	followCollection := database.OpenCollection("follows", client)
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	follows := map[string][]exportFollow{}
+	for key, field := range map[string]string{"following": "follower_id", "followers": "followee_id"} {
+		cursor, err := followCollection.Find(ctx, bson.M{field: userID}, opts)
+		if err != nil {
+			return err
+		}
+
+		edges := []models.Follow{}
+		if err := cursor.All(ctx, &edges); err != nil {
+			return err
+		}
+
+		list := []exportFollow{}
+		for _, edge := range edges {
+			other := edge.FolloweeID
+			if key == "followers" {
+				other = edge.FollowerID
+			}
+			list = append(list, exportFollow{UserID: other.Hex(), FollowedAt: edge.CreatedAt})
+		}
+		follows[key] = list
+	}
+
+	return writeZipJSON(archive, "follows.json", follows)
+}
+
+// writeExportMessages writes one file per room and streams the messages so
+// long histories never sit in memory at once.
+func writeExportMessages(ctx context.Context, client *mongo.Client, archive *zip.Writer, userID bson.ObjectID) error {
+	roomCursor, err := database.OpenCollection("chat_rooms", client).Find(ctx, bson.M{"participants": userID})
+	if err != nil {
+		return err
+	}
+
+	rooms := []models.ChatRoom{}
+	if err := roomCursor.All(ctx, &rooms); err != nil {
+		return err
+	}
+
+	names, err := participantNames(ctx, client, rooms)
+	if err != nil {
+		return err
+	}
+
+	msgCollection := database.OpenCollection("messages", client)
+
+	for _, room := range rooms {
+		participants := []exportParticipant{}
+		for _, id := range room.Participants {
+			participants = append(participants, exportParticipant{ID: id.Hex(), Name: names[id]})
+		}
+
+		header, err := json.Marshal(participants)
+		if err != nil {
+			return err
+		}
+
+		w, err := archive.Create("messages/" + room.ID.Hex() + ".json")
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(w, "{\"room_id\":%q,\"participants\":%s,\"messages\":[", room.ID.Hex(), header); err != nil {
+			return err
+		}
+
+		cursor, err := msgCollection.Find(
+			ctx,
+			bson.M{"room_id": room.ID},
+			options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}),
+		)
+		if err != nil {
+			return err
+		}
+
+		first := true
+		for cursor.Next(ctx) {
+			var msg models.Message
+			if err := cursor.Decode(&msg); err != nil {
+				cursor.Close(ctx)
+				return err
+			}
+
+			line, err := json.Marshal(msg)
+			if err != nil {
+				cursor.Close(ctx)
+				return err
+			}
+
+			sep := ",\n"
+			if first {
+				sep = "\n"
+				first = false
+			}
+
+			if _, err := w.Write(append([]byte(sep), line...)); err != nil {
+				cursor.Close(ctx)
+				return err
+			}
+		}
+		cursor.Close(ctx)
+		if err := cursor.Err(); err != nil {
+			return err
+		}
+
+		if _, err := w.Write([]byte("\n]}\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func participantNames(ctx context.Context, client *mongo.Client, rooms []models.ChatRoom) (map[bson.ObjectID]string, error) {
+	ids := []bson.ObjectID{}
+	for _, room := range rooms {
+		ids = append(ids, room.Participants...)
+	}
+
+	names := map[bson.ObjectID]string{}
+	if len(ids) == 0 {
+		return names, nil
+	}
+
+	cursor, err := database.OpenCollection("users", client).Find(
+		ctx,
+		bson.M{"_id": bson.M{"$in": ids}},
+		options.Find().SetProjection(bson.M{"name": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var user struct {
+			ID   bson.ObjectID `bson:"_id"`
+			Name string        `bson:"name"`
+		}
+		if err := cursor.Decode(&user); err == nil {
+			names[user.ID] = user.Name
+		}
+	}
+	return names, cursor.Err()
+}
+
+func writeZipFile(archive *zip.Writer, name string, data []byte) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func writeZipJSON(archive *zip.Writer, name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeZipFile(archive, name, append(data, '\n'))
+}
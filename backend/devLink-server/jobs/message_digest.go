@@ -0,0 +1,152 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	// Messages younger than this may still be read in the open chat.
+	digestDelay = 15 * time.Minute
+	// Older unread messages are not worth a digest anymore.
+	digestLookback = 7 * 24 * time.Hour
+)
+
+// StartMessageDigest emails every user a summary of unread messages once
+// per interval until ctx is cancelled.
+func StartMessageDigest(ctx context.Context, client *mongo.Client, mailer utils.Mailer, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := SendMessageDigests(ctx, client, mailer, time.Now()); err != nil {
+					log.Println("MESSAGE DIGEST FAILED:", err)
+				}
+			}
+		}
+	}()
+}
+
+type unreadRoom struct {
+	RoomID        bson.ObjectID `bson:"room_id"`
+	Recipient     bson.ObjectID `bson:"recipient"`
+	SenderID      bson.ObjectID `bson:"sender_id"`
+	Count         int           `bson:"count"`
+	LastMessage   string        `bson:"last_message"`
+	LastCreatedAt time.Time     `bson:"last_created_at"`
+}
+
+func SendMessageDigests(ctx context.Context, client *mongo.Client, mailer utils.Mailer, now time.Time) error {
+	msgCol := database.OpenCollection("messages", client)
+	userCol := database.OpenCollection("users", client)
+
+	pipeline := []bson.M{
+		{"$match": bson.M{
+			"seen_at": bson.M{"$exists": false},
+			"created_at": bson.M{
+				"$lte": now.Add(-digestDelay),
+				"$gte": now.Add(-digestLookback),
+			},
+		}},
+		{"$sort": bson.M{"created_at": 1}},
+		{"$lookup": bson.M{
+			"from":         "chat_rooms",
+			"localField":   "room_id",
+			"foreignField": "_id",
+			"as":           "room",
+		}},
+		{"$unwind": "$room"},
+		{"$project": bson.M{
+			"room_id":    1,
+			"sender_id":  1,
+			"content":    1,
+			"created_at": 1,
+			"recipients": bson.M{"$setDifference": bson.A{"$room.participants", bson.A{"$sender_id"}}},
+		}},
+		{"$unwind": "$recipients"},
+		{"$group": bson.M{
+			"_id":             bson.M{"room_id": "$room_id", "recipient": "$recipients"},
+			"room_id":         bson.M{"$first": "$room_id"},
+			"recipient":       bson.M{"$first": "$recipients"},
+			"sender_id":       bson.M{"$last": "$sender_id"},
+			"count":           bson.M{"$sum": 1},
+			"last_message":    bson.M{"$last": "$content"},
+			"last_created_at": bson.M{"$last": "$created_at"},
+		}},
+	}
+
+	cursor, err := msgCol.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+
+	var rooms []unreadRoom
+	if err := cursor.All(ctx, &rooms); err != nil {
+		return err
+	}
+
+	byRecipient := make(map[bson.ObjectID][]unreadRoom)
+	for _, r := range rooms {
+		byRecipient[r.Recipient] = append(byRecipient[r.Recipient], r)
+	}
+
+	names := make(map[bson.ObjectID]string)
+	senderName := func(id bson.ObjectID) string {
+		if name, ok := names[id]; ok {
+			return name
+		}
+		var u models.User
+		if err := userCol.FindOne(ctx, bson.M{"_id": id}).Decode(&u); err != nil {
+			return ""
+		}
+		names[id] = u.UserName
+		return u.UserName
+	}
+
+	for recipientID, unread := range byRecipient {
+		var recipient models.User
+		if err := userCol.FindOne(ctx, bson.M{"_id": recipientID}).Decode(&recipient); err != nil {
+			continue
+		}
+
+		data := utils.MessageDigestEmailData{URL: utils.AppURL("/chat")}
+		for _, r := range unread {
+			if recipient.LastDigestAt != nil && !r.LastCreatedAt.After(*recipient.LastDigestAt) {
+				continue
+			}
+			data.Total += r.Count
+			data.Rooms = append(data.Rooms, utils.DigestRoom{
+				SenderName:  senderName(r.SenderID),
+				Count:       r.Count,
+				LastMessage: r.LastMessage,
+			})
+		}
+
+		if data.Total == 0 {
+			continue
+		}
+
+		if err := utils.SendTemplatedEmail(ctx, mailer, recipient.Email, recipient.Locale, utils.EmailMessageDigest, data); err != nil {
+			log.Println("DIGEST EMAIL FAILED:", err)
+			continue
+		}
+
+		userCol.UpdateOne(ctx, bson.M{"_id": recipientID}, bson.M{
+			"$set": bson.M{"last_digest_at": now},
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// StartPostScheduler publishes scheduled posts whose publish_at has passed
+// once per interval until ctx is cancelled.
+func StartPostScheduler(ctx context.Context, client *mongo.Client, searcher utils.Searcher, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := PublishScheduledPosts(ctx, client, searcher, time.Now()); err != nil {
+					log.Println("POST SCHEDULER FAILED:", err)
+				}
+			}
+		}
+	}()
+}
+
+// PublishScheduledPosts publishes due posts one at a time. Each is claimed
+// by a single update, so several servers can run the job side by side.
+func PublishScheduledPosts(ctx context.Context, client *mongo.Client, searcher utils.Searcher, now time.Time) error {
+	postCollection := database.OpenCollection("posts", client)
+
+	for {
+		// Same as utils.SetPostStatus: a post live for the first time gets
+		// published_at.
+		var post models.Post
+		err := postCollection.FindOneAndUpdate(
+			ctx,
+			bson.M{"status": utils.PostScheduled, "publish_at": bson.M{"$lte": now}},
+			mongo.Pipeline{{{Key: "$set", Value: bson.M{
+				"status":       utils.PostPublished,
+				"published":    true,
+				"publish_at":   nil,
+				"published_at": bson.M{"$ifNull": bson.A{"$published_at", now}},
+				"updated_at":   now,
+			}}}},
+			options.FindOneAndUpdate().
+				SetSort(bson.D{{Key: "publish_at", Value: 1}}).
+				SetReturnDocument(options.After),
+		).Decode(&post)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		log.Println("SCHEDULED POST PUBLISHED:", post.ID.Hex())
+		if err := searcher.Index(ctx, post); err != nil {
+			log.Println("SEARCH INDEX FAILED:", err)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// StartTrendingScores refreshes the trending scores of recent posts right
+// away and then once per interval until ctx is cancelled.
+func StartTrendingScores(ctx context.Context, client *mongo.Client, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := utils.RefreshTrendingScores(ctx, client, time.Now()); err != nil && ctx.Err() == nil {
+				log.Println("TRENDING SCORES FAILED:", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
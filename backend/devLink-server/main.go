@@ -5,12 +5,17 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/jobs"
 	"github.com/ayushmehta03/devLink-backend/routes"
+	"github.com/ayushmehta03/devLink-backend/utils"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 func main() {
@@ -21,6 +26,12 @@ func main() {
 		}
 	}
 
+	keys, err := utils.LoadKeyManager()
+	if err != nil {
+		log.Fatal("JWT key setup failed: ", err)
+	}
+	utils.SetKeys(keys)
+
 	router := gin.Default()
 
 	router.Use(cors.New(cors.Config{
@@ -62,10 +73,75 @@ func main() {
 		}
 	}()
 
-	routes.AuthRoutes(router, client)
+	// Unique indexes are what keep emails, handles and tokens unique under
+	// concurrent writes, so the server does not start without them.
+	indexes := []struct {
+		name   string
+		ensure func(ctx context.Context, client *mongo.Client) error
+	}{
+		{"session", utils.EnsureSessionIndexes},
+		{"email", utils.EnsureEmailIndexes},
+		{"access token", utils.EnsureAccessTokenIndexes},
+		{"oauth", utils.EnsureOAuthIndexes},
+		{"magic link", utils.EnsureMagicLinkIndexes},
+		{"audit", utils.EnsureAuditIndexes},
+		{"moderation", utils.EnsureModerationIndexes},
+		{"data export", utils.EnsureDataExportIndexes},
+		{"handle", utils.EnsureHandleIndexes},
+		{"profile", utils.EnsureProfileIndexes},
+		{"follow", utils.EnsureFollowIndexes},
+		{"feed", utils.EnsureFeedIndexes},
+		{"pagination", utils.EnsurePaginationIndexes},
+		{"chat room", utils.EnsureChatRoomIndexes},
+		{"search", utils.EnsureSearchIndexes},
+		{"post status", utils.EnsurePostStatusIndexes},
+	}
+
+	setupCtx, cancelSetup := context.WithTimeout(context.Background(), 10*time.Second)
+	for _, index := range indexes {
+		if err := index.ensure(setupCtx, client); err != nil {
+			log.Fatal(index.name+" index setup failed: ", err)
+		}
+	}
+	cancelSetup()
+
+	if admins := strings.Fields(strings.ReplaceAll(os.Getenv("ADMIN_EMAILS"), ",", " ")); len(admins) > 0 {
+		adminCtx, cancelAdmin := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := utils.BootstrapAdmins(adminCtx, client, admins); err != nil {
+			log.Println("admin bootstrap failed:", err)
+		}
+		cancelAdmin()
+	}
+
+	mailer, err := utils.NewMailerFromEnv()
+	if err != nil {
+		log.Fatal("Mailer setup failed: ", err)
+	}
+
+	searcher, err := utils.NewSearcherFromEnv(client)
+	if err != nil {
+		log.Fatal("Search setup failed: ", err)
+	}
+
+	routes.AuthRoutes(router, client, mailer)
 	routes.PublicRoutes(router, client)
-	routes.ProtectedRoutes(router, client)
+	routes.ProtectedRoutes(router, client, mailer, searcher)
 	routes.WebSocketRoutes(router, client)
+	routes.AdminRoutes(router, client)
+
+	if os.Getenv("ENV") != "production" {
+		routes.DevRoutes(router)
+	}
+
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	jobs.StartBackfills(jobCtx, client, 10*time.Minute)
+	jobs.StartMessageDigest(jobCtx, client, mailer, time.Hour)
+	jobs.StartAccountPurge(jobCtx, client, time.Hour)
+	jobs.StartDataExports(jobCtx, client, 15*time.Second)
+	jobs.StartPostScheduler(jobCtx, client, searcher, time.Minute)
+	jobs.StartTrendingScores(jobCtx, client, 10*time.Minute)
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -1,53 +1,135 @@
 package middleware
 
 import (
-	"fmt"
+	"context"
 	"net/http"
-	"os"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/ayushmehta03/devLink-backend/utils"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 
 
-func AuthMiddleWare() gin.HandlerFunc {
+func AuthMiddleWare(client *mongo.Client) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
-	
+		if bearer, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			pat, user, err := utils.AuthenticateAccessToken(ctx, client, strings.TrimSpace(bearer))
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired access token"})
+				c.Abort()
+				return
+			}
+
+			if restriction := utils.ActiveRestriction(user.Restriction, time.Now()); restriction != nil {
+				c.JSON(http.StatusForbidden, utils.RestrictionResponse(restriction))
+				c.Abort()
+				return
+			}
+
+			c.Set("user_id", user.Id.Hex())
+			c.Set("email", user.Email)
+			c.Set("role", user.Role)
+			c.Set("auth_method", "token")
+			c.Set("token_scopes", pat.Scopes)
+
+			c.Next()
+			return
+		}
+
 		tokenString, err := c.Cookie("access_token")
-		fmt.Println(tokenString)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
 			c.Abort()
 			return
 		}
 
-		secret := os.Getenv("JWT_SECRET")
+		claims, err := utils.VerifyToken(tokenString)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			c.Abort()
+			return
+		}
+
+		sessionId := claims.SessionID
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(secret), nil
-		})
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
 
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		if !utils.IsSessionActive(ctx, client, sessionId) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session revoked or expired"})
 			c.Abort()
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
+		userId, err := bson.ObjectIDFromHex(claims.UserID)
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
 			c.Abort()
 			return
 		}
 
-		c.Set("user_id", claims["user_id"])
-		c.Set("email", claims["email"])
-		c.Set("role", claims["role"])
+		restriction, err := utils.UserRestriction(ctx, client, userId)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			c.Abort()
+			return
+		}
+		if restriction != nil {
+			c.JSON(http.StatusForbidden, utils.RestrictionResponse(restriction))
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", claims.UserID)
+		c.Set("email", claims.Email)
+		c.Set("role", claims.Role)
+		c.Set("session_id", sessionId)
+		c.Set("auth_method", "session")
+
+		c.Next()
+	}
+}
+
+// RequireScope lets cookie sessions through and limits personal access
+// tokens to routes covered by one of their scopes.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_method") != "token" {
+			c.Next()
+			return
+		}
+
+		if !slices.Contains(c.GetStringSlice("token_scopes"), scope) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Access token is missing the required scope",
+				"scope": scope,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireSession rejects personal access tokens on routes that manage the
+// account itself.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_method") != "session" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This action requires a browser session"})
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole allows users whose role is min or more privileged. It must run
+// after AuthMiddleWare, which puts the role in the context.
+func RequireRole(min string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !utils.RoleAtLeast(c.GetString("role"), min) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient role"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func RequirePermission(perm utils.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !utils.HasPermission(c.GetString("role"), perm) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":      "Missing permission",
+				"permission": perm,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type AuditLog struct {
+	ID         bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	ActorID    bson.ObjectID `bson:"actor_id" json:"actor_id"`
+	ActorRole  string        `bson:"actor_role" json:"actor_role"`
+	Action     string        `bson:"action" json:"action"`
+	TargetType string        `bson:"target_type" json:"target_type"`
+	TargetID   bson.ObjectID `bson:"target_id" json:"target_id"`
+	Reason     string        `bson:"reason,omitempty" json:"reason,omitempty"`
+	Details    bson.M        `bson:"details,omitempty" json:"details,omitempty"`
+	IP         string        `bson:"ip,omitempty" json:"ip,omitempty"`
+	CreatedAt  time.Time     `bson:"created_at" json:"created_at"`
+}
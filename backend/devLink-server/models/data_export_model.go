@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// DataExport tracks one personal data archive from request to download.
+type DataExport struct {
+	ID     bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID bson.ObjectID `bson:"user_id" json:"-"`
+
+	Status   string `bson:"status" json:"status"`
+	Attempts int    `bson:"attempts,omitempty" json:"-"`
+	Error    string `bson:"error,omitempty" json:"error,omitempty"`
+
+	FileID   *bson.ObjectID `bson:"file_id,omitempty" json:"-"`
+	FileName string         `bson:"file_name,omitempty" json:"file_name,omitempty"`
+	Size     int64          `bson:"size,omitempty" json:"size,omitempty"`
+
+	CreatedAt   time.Time  `bson:"created_at" json:"created_at"`
+	StartedAt   *time.Time `bson:"started_at,omitempty" json:"started_at,omitempty"`
+	CompletedAt *time.Time `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+}
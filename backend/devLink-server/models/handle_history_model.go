@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// HandleHistory remembers a handle a user renamed away from so old profile
+// links keep redirecting.
+type HandleHistory struct {
+	ID         bson.ObjectID `bson:"_id,omitempty" json:"-"`
+	Handle     string        `bson:"handle" json:"handle"`
+	UserID     bson.ObjectID `bson:"user_id" json:"-"`
+	ReleasedAt time.Time     `bson:"released_at" json:"released_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type OAuthIdentity struct {
+	ID         bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID     bson.ObjectID `bson:"user_id" json:"user_id"`
+	Provider   string        `bson:"provider" json:"provider"`
+	Subject    string        `bson:"subject" json:"-"`
+	Email      string        `bson:"email" json:"email"`
+	Username   string        `bson:"username,omitempty" json:"username,omitempty"`
+	CreatedAt  time.Time     `bson:"created_at" json:"created_at"`
+	LastUsedAt time.Time     `bson:"last_used_at" json:"last_used_at"`
+}
@@ -25,11 +25,45 @@ type Post struct {
 
 	ImageURL string `bson:"image_url,omitempty" json:"image_url,omitempty"`
 
-	Published bool  `bson:"published" json:"published"`
-	ViewCount int64 `bson:"view_count" json:"view_count"`
+	// Rendered from Content on every save. The HTML is sanitized.
+	ContentHTML    string     `bson:"content_html,omitempty" json:"-"`
+	TOC            []TOCEntry `bson:"toc,omitempty" json:"-"`
+	WordCount      int        `bson:"word_count" json:"word_count"`
+	ReadingMinutes int        `bson:"reading_minutes" json:"reading_minutes"`
+
+	// Status is one of utils.PostStatuses. Published is true exactly when
+	// the status is published and is what public queries filter on.
+	Status      string     `bson:"status" json:"status"`
+	Published   bool       `bson:"published" json:"published"`
+	PublishAt   *time.Time `bson:"publish_at,omitempty" json:"publish_at,omitempty"`
+	PublishedAt *time.Time `bson:"published_at,omitempty" json:"published_at,omitempty"`
+	ViewCount   int64      `bson:"view_count" json:"view_count"`
+
+	// Snapshot of the feed's trending rank, see utils.RefreshTrendingScores.
+	TrendingScore float64 `bson:"trending_score,omitempty" json:"-"`
+
+	// Edits autosaved on a post that is no longer a draft, waiting to be
+	// saved for real.
+	Autosave *PostAutosave `bson:"autosave,omitempty" json:"-"`
 
 	CreatedAt time.Time `bson:"created_at" json:"created_at"`
 	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
 
 
 }
+
+// TOCEntry is a heading of a post, linked by its anchor id.
+type TOCEntry struct {
+	Level int    `bson:"level" json:"level"`
+	ID    string `bson:"id" json:"id"`
+	Text  string `bson:"text" json:"text"`
+}
+
+// PostAutosave is the editor state of a post as last autosaved.
+type PostAutosave struct {
+	Title    string    `bson:"title" json:"title"`
+	Content  string    `bson:"content" json:"content"`
+	ImageURL string    `bson:"image_url,omitempty" json:"image_url,omitempty"`
+	Tags     []string  `bson:"tags" json:"tags"`
+	SavedAt  time.Time `bson:"saved_at" json:"saved_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type Session struct {
+	ID     bson.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID bson.ObjectID `bson:"user_id" json:"user_id"`
+
+	RefreshHash   string   `bson:"refresh_hash" json:"-"`
+	RotatedHashes []string `bson:"rotated_hashes,omitempty" json:"-"`
+
+	UserAgent string `bson:"user_agent" json:"user_agent"`
+	IP        string `bson:"ip" json:"ip"`
+
+	CreatedAt  time.Time  `bson:"created_at" json:"created_at"`
+	LastUsedAt time.Time  `bson:"last_used_at" json:"last_used_at"`
+	ExpiresAt  time.Time  `bson:"expires_at" json:"expires_at"`
+	RevokedAt  *time.Time `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+}
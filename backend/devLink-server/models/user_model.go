@@ -11,22 +11,79 @@ type User struct {
 	UserId string        `bson:"user_id" json:"user_id"`
 
 	UserName string `bson:"name" json:"name" validate:"required,min=5,max=22"`
+	Handle   string `bson:"handle,omitempty" json:"handle,omitempty"`
+	HandleChangedAt *time.Time `bson:"handle_changed_at,omitempty" json:"-"`
 	Email    string `bson:"email" json:"email" validate:"required,email"`
 	Password string `bson:"password" json:"password" validate:"required,min=6"`
 
 	Bio  string `bson:"bio,omitempty" json:"bio"`
 	Role string `bson:"role" json:"role"`
+	Locale string `bson:"locale,omitempty" json:"locale,omitempty"`
     ProfileImage  string `bson:"profile_image" json:"profile_image"`
 
+	Skills      []Skill         `bson:"skills,omitempty" json:"skills,omitempty"`
+	Links       ProfileLinks    `bson:"links" json:"links"`
+	Location    string          `bson:"location,omitempty" json:"location,omitempty"`
+	Timezone    string          `bson:"timezone,omitempty" json:"timezone,omitempty"`
+	OpenToCollaborate bool      `bson:"open_to_collaborate" json:"open_to_collaborate"`
+	OpenToHire  bool            `bson:"open_to_hire" json:"open_to_hire"`
+	PinnedPosts []bson.ObjectID `bson:"pinned_posts,omitempty" json:"pinned_posts,omitempty"`
+	FollowedTags []string       `bson:"followed_tags,omitempty" json:"-"`
+
 
 	IsVerified bool      `bson:"is_verified" json:"is_verified"`
 	OTPHash    string    `bson:"otp_hash,omitempty" json:"-"`
 	OTPExpiry  time.Time `bson:"otp_expiry,omitempty" json:"-"`
+	OTPAttempts int      `bson:"otp_attempts,omitempty" json:"-"`
+	OTPSentAt  time.Time `bson:"otp_sent_at,omitempty" json:"-"`
+
+	ResetOTPHash   string    `bson:"reset_otp_hash,omitempty" json:"-"`
+	ResetOTPExpiry time.Time `bson:"reset_otp_expiry,omitempty" json:"-"`
+	ResetOTPAttempts int    `bson:"reset_otp_attempts,omitempty" json:"-"`
+
+	PendingEmail   string    `bson:"pending_email,omitempty" json:"-"`
+	EmailOTPHash   string    `bson:"email_otp_hash,omitempty" json:"-"`
+	EmailOTPExpiry time.Time `bson:"email_otp_expiry,omitempty" json:"-"`
+	EmailOTPAttempts int    `bson:"email_otp_attempts,omitempty" json:"-"`
+
+	TOTPEnabled       bool     `bson:"totp_enabled" json:"-"`
+	TOTPSecret        string   `bson:"totp_secret,omitempty" json:"-"`
+	TOTPPendingSecret string   `bson:"totp_pending_secret,omitempty" json:"-"`
+	TOTPLastStep      int64    `bson:"totp_last_step,omitempty" json:"-"`
+	RecoveryCodes     []string `bson:"recovery_codes,omitempty" json:"-"`
+
+	Restriction *AccountRestriction `bson:"restriction,omitempty" json:"restriction,omitempty"`
+
+	DeletionRequestedAt *time.Time `bson:"deletion_requested_at,omitempty" json:"-"`
 
 	CreatedAt time.Time `bson:"created_at" json:"created_at"`
 	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
 	LastSeen *time.Time `bson:"last_seen,omitempty" json:"last_seen,omitempty"`
+	LastDigestAt *time.Time `bson:"last_digest_at,omitempty" json:"-"`
+
+}
+
+// Skill is a technology on a developer profile. Names are stored lowercase
+// so that search matches regardless of how they were typed.
+type Skill struct {
+	Name  string `bson:"name" json:"name"`
+	Level string `bson:"level" json:"level"`
+}
+
+type ProfileLinks struct {
+	GitHub  string `bson:"github,omitempty" json:"github,omitempty"`
+	GitLab  string `bson:"gitlab,omitempty" json:"gitlab,omitempty"`
+	Website string `bson:"website,omitempty" json:"website,omitempty"`
+}
 
+// AccountRestriction is a suspension or ban placed by a moderator. A nil
+// Until means it lasts until lifted.
+type AccountRestriction struct {
+	Status    string        `bson:"status" json:"status"`
+	Reason    string        `bson:"reason" json:"reason"`
+	Until     *time.Time    `bson:"until,omitempty" json:"until,omitempty"`
+	CreatedAt time.Time     `bson:"created_at" json:"created_at"`
+	CreatedBy bson.ObjectID `bson:"created_by" json:"-"`
 }
 
 type UserLogin struct {
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/ayushmehta03/devLink-backend/controllers"
+	"github.com/ayushmehta03/devLink-backend/middleware"
+	"github.com/ayushmehta03/devLink-backend/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func AdminRoutes(router *gin.Engine, client *mongo.Client) {
+	admin := router.Group("/api/admin")
+	admin.Use(
+		middleware.AuthMiddleWare(client),
+		middleware.RequireSession(),
+		middleware.RequireRole(utils.RoleModerator),
+	)
+
+	admin.GET("/users", middleware.RequirePermission(utils.PermUsersList), controllers.AdminListUsers(client))
+	admin.POST("/users/:id/suspend", middleware.RequirePermission(utils.PermUsersSuspend), controllers.AdminRestrictUser(client, utils.StatusSuspended))
+	admin.POST("/users/:id/unsuspend", middleware.RequirePermission(utils.PermUsersSuspend), controllers.AdminLiftRestriction(client, utils.StatusSuspended))
+	admin.POST("/users/:id/ban", middleware.RequirePermission(utils.PermUsersBan), controllers.AdminRestrictUser(client, utils.StatusBanned))
+	admin.POST("/users/:id/unban", middleware.RequirePermission(utils.PermUsersBan), controllers.AdminLiftRestriction(client, utils.StatusBanned))
+	admin.POST("/users/:id/verify", middleware.RequirePermission(utils.PermUsersVerify), controllers.AdminVerifyUser(client))
+	admin.PUT("/users/:id/role", middleware.RequirePermission(utils.PermUsersSetRole), controllers.AdminSetUserRole(client))
+
+	admin.POST("/posts/:id/unpublish", middleware.RequirePermission(utils.PermPostsModerate), controllers.AdminUnpublishPost(client))
+
+	admin.GET("/audit", middleware.RequirePermission(utils.PermAuditRead), controllers.AdminAuditLog(client))
+}
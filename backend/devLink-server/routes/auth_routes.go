@@ -2,17 +2,58 @@ package routes
 
 import (
 	"github.com/ayushmehta03/devLink-backend/controllers"
+	"github.com/ayushmehta03/devLink-backend/middleware"
+	"github.com/ayushmehta03/devLink-backend/utils"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
-func AuthRoutes(router *gin.Engine, client *mongo.Client) {
+func AuthRoutes(router *gin.Engine, client *mongo.Client, mailer utils.Mailer) {
+	router.GET("/.well-known/jwks.json", controllers.GetJWKS())
+
 	auth := router.Group("/api/auth")
 
-	auth.POST("/register", controllers.RegisterUser(client))
+	auth.POST("/register", controllers.RegisterUser(client, mailer))
 	auth.POST("/verify-otp", controllers.VerifyOtp(client))
-	auth.POST("/resend-otp", controllers.ResendOtp(client))
+	auth.POST("/resend-otp", controllers.ResendOtp(client, mailer))
 	auth.POST("/login", controllers.LoginUser(client))
+	auth.POST("/2fa/verify", controllers.VerifyMFA(client))
+	auth.POST("/magic/request", controllers.RequestMagicLink(client, mailer))
+	auth.POST("/magic", controllers.MagicLinkLogin(client))
 	auth.GET("/me", controllers.GetMe(client))
-	auth.POST("/logout", controllers.LogoutUser())
+	auth.POST("/forgot-password", controllers.ForgotPassword(client, mailer))
+	auth.POST("/reset-password", controllers.ResetPassword(client))
+	auth.POST("/refresh", controllers.RefreshToken(client))
+	auth.POST("/logout", controllers.LogoutUser(client))
+	auth.GET("/jwks", controllers.GetJWKS())
+
+	providers := utils.OAuthProvidersFromEnv()
+	auth.GET("/oauth", controllers.ListOAuthProviders(providers))
+	auth.GET("/oauth/:provider", controllers.OAuthStart(providers))
+	auth.GET("/oauth/:provider/callback", controllers.OAuthCallback(client, mailer, providers))
+
+	account := auth.Group("")
+	account.Use(middleware.AuthMiddleWare(client), middleware.RequireSession())
+
+	account.GET("/sessions", controllers.GetSessions(client))
+	account.DELETE("/sessions", controllers.RevokeOtherSessions(client))
+	account.DELETE("/sessions/:id", controllers.RevokeSession(client))
+
+	account.DELETE("/account", controllers.DeleteAccount(client))
+
+	account.POST("/export", controllers.RequestDataExport(client))
+	account.GET("/export/:id", controllers.GetDataExport(client))
+	account.GET("/export/:id/download", controllers.DownloadDataExport(client))
+
+	account.PUT("/password", controllers.ChangePassword(client))
+	account.POST("/email/change", controllers.RequestEmailChange(client, mailer))
+	account.POST("/email/verify", controllers.VerifyEmailChange(client))
+
+	account.POST("/2fa/setup", controllers.SetupTOTP(client))
+	account.POST("/2fa/confirm", controllers.ConfirmTOTP(client))
+	account.POST("/2fa/disable", controllers.DisableTOTP(client))
+
+	account.GET("/tokens", controllers.ListAccessTokens(client))
+	account.POST("/tokens", controllers.CreateAccessToken(client))
+	account.DELETE("/tokens/:id", controllers.RevokeAccessToken(client))
 }
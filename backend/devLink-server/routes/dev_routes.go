@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/ayushmehta03/devLink-backend/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+// DevRoutes exposes tooling for designers and developers. It is only
+// registered outside production.
+func DevRoutes(router *gin.Engine) {
+	dev := router.Group("/api/dev")
+
+	dev.GET("/emails", controllers.ListEmailPreviews())
+	dev.GET("/emails/:name", controllers.PreviewEmail())
+}
@@ -3,42 +3,57 @@ package routes
 import (
 	"github.com/ayushmehta03/devLink-backend/controllers"
 	"github.com/ayushmehta03/devLink-backend/middleware"
+	"github.com/ayushmehta03/devLink-backend/utils"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 
-func ProtectedRoutes(router *gin.Engine, client *mongo.Client) {
+func ProtectedRoutes(router *gin.Engine, client *mongo.Client, mailer utils.Mailer, searcher utils.Searcher) {
 	protected := router.Group("/api")
-	protected.Use(middleware.AuthMiddleWare())
-
-	protected.GET("/posts", controllers.GetAllPosts(client))
-	protected.GET("/users/:userId", controllers.GetUserProfile(client))
-	protected.GET("/search/users", controllers.SearchUsers(client))
-	protected.GET("/posts/tags", controllers.SearchPost(client))
-	protected.GET("/posts/trending", controllers.GetTrendingPosts(client))
-	protected.GET("/posts/me", controllers.GetMyPosts(client))
-	protected.GET("/users/:userId/stats", controllers.GetUserProfileStats(client))
-
-	protected.POST("/createpost", controllers.CreatePost(client))
-	protected.PUT("/updatepost/:id", controllers.UpdatePost(client))
-	protected.DELETE("/deletepost/:id", controllers.DeletePost(client))
-
-	protected.GET("/posts/archive", controllers.GetArchivePosts(client))
-
-	protected.POST("/chat/request", controllers.SendChatRequest(client))
-	protected.GET("/chat/requests", controllers.ReceiveChatRequest(client))
-	protected.POST("/chat/request/:id/respond", controllers.RespondChatRequest(client))
-	protected.GET("/chat/request/status/:userId", controllers.GetChatRequestStatus(client))
-
-	protected.GET("/chat/rooms/:room_id/messages", controllers.ChatHistory(client))
-	protected.POST("/chat/rooms/:room_id/seen", controllers.MarkSeenMsg(client))
-	protected.GET("/chat/counts", controllers.GetChatCounts(client))
-	protected.GET("/chatrooms", controllers.GetChatRooms(client))
-
-	protected.GET("/users/suggested", controllers.GetSuggestedUsers(client))
-
-	protected.GET("/ws/token", controllers.GetWSToken())
-
-	protected.PUT("/update-profile", controllers.UpdateProfile(client))
+	protected.Use(middleware.AuthMiddleWare(client))
+
+	protected.GET("/posts", middleware.RequireScope(utils.ScopePostsRead), controllers.GetAllPosts(client))
+	protected.GET("/users/:userId", middleware.RequireScope(utils.ScopeUsersRead), controllers.GetUserProfile(client))
+	protected.GET("/search/users", middleware.RequireScope(utils.ScopeUsersRead), controllers.SearchUsers(client))
+	protected.GET("/posts/search", middleware.RequireScope(utils.ScopePostsRead), controllers.SearchPost(client, searcher))
+	protected.GET("/posts/tags", middleware.RequireScope(utils.ScopePostsRead), controllers.SearchPost(client, searcher))
+	protected.GET("/posts/trending", middleware.RequireScope(utils.ScopePostsRead), controllers.GetTrendingPosts(client))
+	protected.GET("/feed", middleware.RequireScope(utils.ScopePostsRead), controllers.GetFeed(client))
+	protected.GET("/tags/following", middleware.RequireScope(utils.ScopeUsersRead), controllers.GetFollowedTags(client))
+	protected.POST("/tags/:tag/follow", middleware.RequireScope(utils.ScopeFollowsWrite), controllers.FollowTag(client))
+	protected.DELETE("/tags/:tag/follow", middleware.RequireScope(utils.ScopeFollowsWrite), controllers.UnfollowTag(client))
+	protected.GET("/posts/me", middleware.RequireScope(utils.ScopePostsRead), controllers.GetMyPosts(client))
+	protected.GET("/users/:userId/stats", middleware.RequireScope(utils.ScopeUsersRead), controllers.GetUserProfileStats(client))
+	protected.GET("/users/:userId/followers", middleware.RequireScope(utils.ScopeUsersRead), controllers.GetFollowers(client))
+	protected.GET("/users/:userId/following", middleware.RequireScope(utils.ScopeUsersRead), controllers.GetFollowing(client))
+	protected.POST("/users/:userId/follow", middleware.RequireScope(utils.ScopeFollowsWrite), controllers.FollowUser(client))
+	protected.DELETE("/users/:userId/follow", middleware.RequireScope(utils.ScopeFollowsWrite), controllers.UnfollowUser(client))
+
+	protected.POST("/createpost", middleware.RequireScope(utils.ScopePostsWrite), controllers.CreatePost(client, searcher))
+	protected.PUT("/updatepost/:id", middleware.RequireScope(utils.ScopePostsWrite), controllers.UpdatePost(client, searcher))
+	protected.DELETE("/deletepost/:id", middleware.RequireScope(utils.ScopePostsWrite), controllers.DeletePost(client, searcher))
+
+	protected.GET("/posts/archive", middleware.RequireScope(utils.ScopePostsRead), controllers.GetArchivePosts(client))
+	protected.GET("/posts/content", middleware.RequireScope(utils.ScopePostsRead), controllers.GetArchivePosts(client))
+
+	protected.GET("/autosave/:id", middleware.RequireScope(utils.ScopePostsRead), controllers.GetAutosave(client))
+	protected.PUT("/autosave/:id", middleware.RequireScope(utils.ScopePostsWrite), controllers.AutosavePost(client))
+	protected.DELETE("/autosave/:id", middleware.RequireScope(utils.ScopePostsWrite), controllers.DiscardAutosave(client))
+
+	protected.POST("/chat/request", middleware.RequireScope(utils.ScopeChatWrite), controllers.SendChatRequest(client, mailer))
+	protected.GET("/chat/requests", middleware.RequireScope(utils.ScopeChatRead), controllers.ReceiveChatRequest(client))
+	protected.POST("/chat/request/:id/respond", middleware.RequireScope(utils.ScopeChatWrite), controllers.RespondChatRequest(client))
+	protected.GET("/chat/request/status/:userId", middleware.RequireScope(utils.ScopeChatRead), controllers.GetChatRequestStatus(client))
+
+	protected.GET("/chat/rooms/:room_id/messages", middleware.RequireScope(utils.ScopeChatRead), controllers.ChatHistory(client))
+	protected.POST("/chat/rooms/:room_id/seen", middleware.RequireScope(utils.ScopeChatWrite), controllers.MarkSeenMsg(client))
+	protected.GET("/chat/counts", middleware.RequireScope(utils.ScopeChatRead), controllers.GetChatCounts(client))
+	protected.GET("/chatrooms", middleware.RequireScope(utils.ScopeChatRead), controllers.GetChatRooms(client))
+
+	protected.GET("/users/suggested", middleware.RequireScope(utils.ScopeUsersRead), controllers.GetSuggestedUsers(client))
+
+	protected.GET("/ws/token", middleware.RequireScope(utils.ScopeChatWrite), controllers.GetWSToken())
+
+	protected.PUT("/update-profile", middleware.RequireScope(utils.ScopeProfileWrite), controllers.UpdateProfile(client))
 }
@@ -12,4 +12,5 @@ func PublicRoutes(router *gin.Engine, client *mongo.Client) {
 
 	api.GET("/home", controllers.GetHomeFeed(client))
 	api.GET("/posts/:slug", controllers.GetPostBySlug(client))
+	api.GET("/u/:handle", controllers.GetUserByHandle(client))
 }
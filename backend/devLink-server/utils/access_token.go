@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// AccessTokenPrefix marks personal access tokens so they can be told apart
+// from JWTs and spotted by secret scanners.
+const AccessTokenPrefix = "dlp_"
+
+const (
+	ScopePostsRead    = "posts:read"
+	ScopePostsWrite   = "posts:write"
+	ScopeUsersRead    = "users:read"
+	ScopeProfileWrite = "profile:write"
+	ScopeChatRead     = "chat:read"
+	ScopeChatWrite    = "chat:write"
+	ScopeFollowsWrite = "follows:write"
+)
+
+var AccessTokenScopes = []string{
+	ScopePostsRead,
+	ScopePostsWrite,
+	ScopeUsersRead,
+	ScopeProfileWrite,
+	ScopeChatRead,
+	ScopeChatWrite,
+	ScopeFollowsWrite,
+}
+
+var ErrInvalidAccessToken = errors.New("invalid or expired access token")
+
+func ValidAccessTokenScope(scope string) bool {
+	return slices.Contains(AccessTokenScopes, scope)
+}
+
+func GenerateAccessToken() (string, error) {
+	secret, err := GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	return AccessTokenPrefix + secret, nil
+}
+
+// EnsureAccessTokenIndexes backs the token lookup done on every Bearer
+// request and the per-user token list.
+func EnsureAccessTokenIndexes(ctx context.Context, client *mongo.Client) error {
+	_, err := database.OpenCollection("access_tokens", client).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	return err
+}
+
+// AuthenticateAccessToken resolves a raw personal access token to its
+// stored record and owner.
+func AuthenticateAccessToken(ctx context.Context, client *mongo.Client, raw string) (models.PersonalAccessToken, models.User, error) {
+	var pat models.PersonalAccessToken
+	var user models.User
+
+	if !strings.HasPrefix(raw, AccessTokenPrefix) {
+		return pat, user, ErrInvalidAccessToken
+	}
+
+	tokenCol := database.OpenCollection("access_tokens", client)
+
+	now := time.Now()
+	err := tokenCol.FindOne(ctx, bson.M{
+		"token_hash": HashToken(raw),
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": now},
+	}).Decode(&pat)
+	if err != nil {
+		return pat, user, ErrInvalidAccessToken
+	}
+
+	if err := database.OpenCollection("users", client).FindOne(ctx, bson.M{"_id": pat.UserID}).Decode(&user); err != nil {
+		return pat, user, ErrInvalidAccessToken
+	}
+
+	// Tokens stop working while the account is scheduled for deletion.
+	if user.DeletionRequestedAt != nil {
+		return pat, user, ErrInvalidAccessToken
+	}
+
+	tokenCol.UpdateOne(
+		ctx,
+		bson.M{
+			"_id": pat.ID,
+			"$or": []bson.M{
+				{"last_used_at": bson.M{"$exists": false}},
+				{"last_used_at": bson.M{"$lt": now.Add(-time.Minute)}},
+			},
+		},
+		bson.M{"$set": bson.M{"last_used_at": now}},
+	)
+
+	return pat, user, nil
+}
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// AccountDeletionGrace is how long a deleted account can still be restored by
+// logging in before its data is purged.
+const AccountDeletionGrace = 14 * 24 * time.Hour
+
+var (
+	// ErrAccountDeleted is returned when logging in to an account whose
+	// grace period is over; it can no longer be restored.
+	ErrAccountDeleted = errors.New("account deleted")
+
+	// ErrPurgeCancelled is returned when an account stops being due for
+	// purging, because it was restored, while the purge runs.
+	ErrPurgeCancelled = errors.New("account no longer due for purging")
+)
+
+// PurgeAccount removes everything the user owns, provided the user asked
+// for deletion at or before cutoff. That is checked again before each step
+// and by the final delete, so a restored account keeps its data. Chat rooms
+// are one-to-one, so a room and its whole history go with either
+// participant. The user document is deleted last, which lets an
+// interrupted purge be retried.
+func PurgeAccount(ctx context.Context, client *mongo.Client, userID bson.ObjectID, cutoff time.Time) error {
+	userCollection := database.OpenCollection("users", client)
+	due := bson.M{"_id": userID, "deletion_requested_at": bson.M{"$lte": cutoff}}
+
+	stillDue := func() error {
+		count, err := userCollection.CountDocuments(ctx, due)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrPurgeCancelled
+		}
+		return nil
+	}
+
+	if err := stillDue(); err != nil {
+		return err
+	}
+
+	roomCursor, err := database.OpenCollection("chat_rooms", client).Find(
+		ctx,
+		bson.M{"participants": userID},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return err
+	}
+
+	roomIDs := []bson.ObjectID{}
+	for roomCursor.Next(ctx) {
+		var room struct {
+			ID bson.ObjectID `bson:"_id"`
+		}
+		if err := roomCursor.Decode(&room); err == nil {
+			roomIDs = append(roomIDs, room.ID)
+		}
+	}
+	roomCursor.Close(ctx)
+	if err := roomCursor.Err(); err != nil {
+		return err
+	}
+
+	if err := stillDue(); err != nil {
+		return err
+	}
+	if err := DeleteDataExports(ctx, client, bson.M{"user_id": userID}); err != nil {
+		return err
+	}
+
+	deletes := []struct {
+		collection string
+		filter     bson.M
+	}{
+		{"messages", bson.M{"$or": []bson.M{
+			{"room_id": bson.M{"$in": roomIDs}},
+			{"sender_id": userID},
+		}}},
+		{"chat_rooms", bson.M{"_id": bson.M{"$in": roomIDs}}},
+		{"chat_requests", bson.M{"$or": []bson.M{
+			{"sender_id": userID},
+			{"receiver_id": userID},
+		}}},
+		{"posts", bson.M{"author_id": userID}},
+		{"sessions", bson.M{"user_id": userID}},
+		{"access_tokens", bson.M{"user_id": userID}},
+		{"oauth_identities", bson.M{"user_id": userID}},
+		{"magic_links", bson.M{"user_id": userID}},
+		{"handle_history", bson.M{"user_id": userID}},
+		{"follows", bson.M{"$or": []bson.M{
+			{"follower_id": userID},
+			{"followee_id": userID},
+		}}},
+	}
+
+	for _, d := range deletes {
+		if err := stillDue(); err != nil {
+			return err
+		}
+		if _, err := database.OpenCollection(d.collection, client).DeleteMany(ctx, d.filter); err != nil {
+			return err
+		}
+	}
+
+	res, err := userCollection.DeleteOne(ctx, due)
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrPurgeCancelled
+	}
+	return nil
+}
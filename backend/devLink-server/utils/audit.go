@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"context"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	AuditUserSuspend   = "user.suspend"
+	AuditUserUnsuspend = "user.unsuspend"
+	AuditUserBan       = "user.ban"
+	AuditUserUnban     = "user.unban"
+	AuditUserVerify    = "user.verify"
+	AuditUserSetRole   = "user.set_role"
+	AuditPostUnpublish = "post.unpublish"
+)
+
+// WriteAuditLog appends entry to the audit log. The log is append-only,
+// nothing in the API updates or deletes entries.
+func WriteAuditLog(ctx context.Context, client *mongo.Client, entry models.AuditLog) error {
+	entry.ID = bson.NewObjectID()
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	_, err := database.OpenCollection("audit_logs", client).InsertOne(ctx, entry)
+	return err
+}
+
+// EnsureAuditIndexes supports the audit log filters.
+func EnsureAuditIndexes(ctx context.Context, client *mongo.Client) error {
+	_, err := database.OpenCollection("audit_logs", client).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "target_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	return err
+}
+
+// BootstrapAdmins grants the admin role to the listed emails. Admins can only
+// grant roles below their own, so this is how the first admin is created.
+func BootstrapAdmins(ctx context.Context, client *mongo.Client, emails []string) error {
+	if len(emails) == 0 {
+		return nil
+	}
+
+	_, err := database.OpenCollection("users", client).UpdateMany(
+		ctx,
+		bson.M{"email": bson.M{"$in": emails}, "role": bson.M{"$ne": RoleAdmin}},
+		bson.M{"$set": bson.M{"role": RoleAdmin, "updated_at": time.Now()}},
+	)
+	return err
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// RoomPairKey identifies the room of two users whichever of them made
+// contact first.
+func RoomPairKey(a, b bson.ObjectID) string {
+	x, y := a.Hex(), b.Hex()
+	if x > y {
+		x, y = y, x
+	}
+	return x + ":" + y
+}
+
+// EnsureChatRoomIndexes keeps two users to a single room. Rooms created
+// before pair keys existed get theirs when next opened, hence the partial
+// filter.
+func EnsureChatRoomIndexes(ctx context.Context, client *mongo.Client) error {
+	_, err := database.OpenCollection("chat_rooms", client).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "pair_key", Value: 1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"pair_key": bson.M{"$type": "string"}}),
+	})
+	return err
+}
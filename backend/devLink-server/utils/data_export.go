@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	ExportPending = "pending"
+	ExportRunning = "running"
+	ExportReady   = "ready"
+	ExportFailed  = "failed"
+
+	// DataExportTTL is how long a finished archive stays downloadable.
+	DataExportTTL = 7 * 24 * time.Hour
+)
+
+// ExportBucket is the GridFS bucket holding generated archives.
+func ExportBucket(client *mongo.Client) *mongo.GridFSBucket {
+	return database.OpenCollection("data_exports", client).Database().GridFSBucket(
+		options.GridFSBucket().SetName("export_files"),
+	)
+}
+
+// EnsureDataExportIndexes supports the per-user listing and the worker's
+// queue scan, and allows each user one pending or running export.
+func EnsureDataExportIndexes(ctx context.Context, client *mongo.Client) error {
+	_, err := database.OpenCollection("data_exports", client).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().
+				SetName("user_id_active").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": bson.M{"$in": bson.A{ExportPending, ExportRunning}}}),
+		},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+	})
+	return err
+}
+
+// DeleteDataExports removes the matching export records together with their
+// archives.
+func DeleteDataExports(ctx context.Context, client *mongo.Client, filter bson.M) error {
+	exportCollection := database.OpenCollection("data_exports", client)
+
+	cursor, err := exportCollection.Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	exports := []models.DataExport{}
+	if err := cursor.All(ctx, &exports); err != nil {
+		return err
+	}
+
+	bucket := ExportBucket(client)
+	for _, export := range exports {
+		if export.FileID != nil {
+			if err := bucket.Delete(ctx, *export.FileID); err != nil && !errors.Is(err, mongo.ErrFileNotFound) {
+				return err
+			}
+		}
+		if _, err := exportCollection.DeleteOne(ctx, bson.M{"_id": export.ID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,91 +2,228 @@ package utils
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
+	"embed"
 	"fmt"
-	"net/http"
+	htmltemplate "html/template"
 	"os"
+	"strings"
+	"sync"
+	texttemplate "text/template"
 )
 
-func SendOTPEmail(toEmail string, otp string) error {
+//go:embed emails
+var emailFS embed.FS
 
-	apiKey := os.Getenv("RESEND_API_KEY")
-	from := os.Getenv("EMAIL_FROM")
+const DefaultLocale = "en"
 
-	if apiKey == "" || from == "" {
-		return fmt.Errorf("resend env variables not set")
+var SupportedLocales = []string{"en", "es"}
+
+const (
+	EmailVerification  = "verification"
+	EmailPasswordReset = "password_reset"
+	EmailChatRequest   = "chat_request"
+	EmailMessageDigest = "message_digest"
+	EmailMagicLink     = "magic_link"
+)
+
+var EmailTemplates = []string{
+	EmailVerification,
+	EmailPasswordReset,
+	EmailChatRequest,
+	EmailMessageDigest,
+	EmailMagicLink,
+}
+
+type OTPEmailData struct {
+	OTP          string
+	ValidMinutes int
+}
+
+type ChatRequestEmailData struct {
+	SenderName string
+	Message    string
+	URL        string
+}
+
+type DigestRoom struct {
+	SenderName  string
+	Count       int
+	LastMessage string
+}
+
+type MessageDigestEmailData struct {
+	Total int
+	Rooms []DigestRoom
+	URL   string
+}
+
+type MagicLinkEmailData struct {
+	URL          string
+	ValidMinutes int
+}
+
+type emailContext struct {
+	Locale string
+	Data   any
+}
+
+type emailTemplate struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+var (
+	emailTemplatesOnce sync.Once
+	emailTemplatesErr  error
+	emailTemplateSet   map[string]emailTemplate
+)
+
+var emailFuncs = map[string]any{
+	"otpBox": func(label, otp, validity string) map[string]string {
+		return map[string]string{"Label": label, "OTP": otp, "Validity": validity}
+	},
+	"button": func(label, url string) map[string]string {
+		return map[string]string{"Label": label, "URL": url}
+	},
+}
+
+func loadEmailTemplates() {
+	emailTemplateSet = make(map[string]emailTemplate)
+
+	for _, locale := range SupportedLocales {
+		for _, name := range EmailTemplates {
+			html, err := htmltemplate.New("layout.html").
+				Funcs(htmltemplate.FuncMap(emailFuncs)).
+				ParseFS(emailFS, "emails/layout.html", "emails/"+locale+"/"+name+".html")
+			if err != nil {
+				emailTemplatesErr = err
+				return
+			}
+
+			text, err := texttemplate.New(name+".txt").
+				Funcs(texttemplate.FuncMap(emailFuncs)).
+				ParseFS(emailFS, "emails/"+locale+"/"+name+".txt")
+			if err != nil {
+				emailTemplatesErr = err
+				return
+			}
+
+			emailTemplateSet[locale+"/"+name] = emailTemplate{html: html, text: text}
+		}
 	}
+}
 
-	payload := map[string]interface{}{
-		"from": from,
-		"to":   []string{toEmail},
-		"subject": "DevLink • Verify your email",
-		"html": fmt.Sprintf(`
-<!DOCTYPE html>
-<html>
-<body style="background:#0f172a; font-family:Arial; padding:40px;">
-  <div style="max-width:520px; margin:auto; background:#111827; padding:28px; border-radius:16px; color:#fff;">
-    <h2 style="text-align:center;">
-      Dev<span style="color:#3b82f6;">Link</span>
-    </h2>
-
-    <p style="text-align:center; color:#94a3b8;">
-      Email verification required
-    </p>
-
-    <div style="margin:32px 0; text-align:center;">
-      <p style="color:#cbd5f5;">Your One-Time Password:</p>
-
-      <div style="
-        font-size:28px;
-        font-weight:bold;
-        letter-spacing:6px;
-        color:#3b82f6;
-        background:#020617;
-        padding:16px 32px;
-        border-radius:12px;
-        display:inline-block;
-      ">
-        %s
-      </div>
-
-      <p style="font-size:12px; color:#94a3b8; margin-top:12px;">
-        Valid for 10 minutes
-      </p>
-    </div>
-
-    <p style="font-size:12px; color:#64748b; text-align:center;">
-      If you didn’t request this, ignore this email.
-    </p>
-  </div>
-</body>
-</html>
-`, otp),
+// ResolveLocale returns the first supported locale among the candidates,
+// which may be plain tags ("es-MX") or Accept-Language header values.
+func ResolveLocale(candidates ...string) string {
+	for _, candidate := range candidates {
+		for _, part := range strings.Split(candidate, ",") {
+			tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+			tag = strings.ToLower(tag)
+			tag, _, _ = strings.Cut(tag, "-")
+			tag, _, _ = strings.Cut(tag, "_")
+
+			for _, supported := range SupportedLocales {
+				if tag == supported {
+					return supported
+				}
+			}
+		}
 	}
+	return DefaultLocale
+}
 
-	body, _ := json.Marshal(payload)
+// RenderEmail renders the named template in the given locale, falling back
+// to the default locale for unsupported ones.
+func RenderEmail(name, locale string, data any) (Email, error) {
+	emailTemplatesOnce.Do(loadEmailTemplates)
+	if emailTemplatesErr != nil {
+		return Email{}, emailTemplatesErr
+	}
 
-	req, err := http.NewRequest(
-		"POST",
-		"https://api.resend.com/emails",
-		bytes.NewBuffer(body),
-	)
-	if err != nil {
-		return err
+	locale = ResolveLocale(locale)
+
+	tmpl, ok := emailTemplateSet[locale+"/"+name]
+	if !ok {
+		return Email{}, fmt.Errorf("unknown email template %q", name)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+apiKey)
-	req.Header.Set("Content-Type", "application/json")
+	ctx := emailContext{Locale: locale, Data: data}
+
+	var subject, text, html bytes.Buffer
 
-	resp, err := http.DefaultClient.Do(req)
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", ctx); err != nil {
+		return Email{}, err
+	}
+	if err := tmpl.text.ExecuteTemplate(&text, "body", ctx); err != nil {
+		return Email{}, err
+	}
+	if err := tmpl.html.ExecuteTemplate(&html, "layout", ctx); err != nil {
+		return Email{}, err
+	}
+
+	return Email{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+func SendTemplatedEmail(ctx context.Context, mailer Mailer, toEmail, locale, name string, data any) error {
+	msg, err := RenderEmail(name, locale, data)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
+	msg.To = toEmail
+	return mailer.Send(ctx, msg)
+}
+
+func SendOTPEmail(ctx context.Context, mailer Mailer, toEmail, locale, otp string) error {
+	return SendTemplatedEmail(ctx, mailer, toEmail, locale, EmailVerification, OTPEmailData{
+		OTP:          otp,
+		ValidMinutes: 10,
+	})
+}
+
+func SendPasswordResetEmail(ctx context.Context, mailer Mailer, toEmail, locale, otp string) error {
+	return SendTemplatedEmail(ctx, mailer, toEmail, locale, EmailPasswordReset, OTPEmailData{
+		OTP:          otp,
+		ValidMinutes: 10,
+	})
+}
 
-	if resp.StatusCode >= 300 {
-		return fmt.Errorf("resend failed with status %d", resp.StatusCode)
+// AppURL builds a link into the web client configured by FRONTEND_URL.
+func AppURL(path string) string {
+	base := os.Getenv("FRONTEND_URL")
+	if base == "" {
+		base = "http://localhost:3000"
 	}
+	return strings.TrimRight(base, "/") + path
+}
 
-	return nil
+// SampleEmailData returns representative data for previewing a template.
+func SampleEmailData(name string) (any, bool) {
+	switch name {
+	case EmailVerification, EmailPasswordReset:
+		return OTPEmailData{OTP: "123456", ValidMinutes: 10}, true
+	case EmailChatRequest:
+		return ChatRequestEmailData{
+			SenderName: "ada_lovelace",
+			Message:    "Loved your post on Go generics, want to pair on something?",
+			URL:        AppURL("/chat"),
+		}, true
+	case EmailMessageDigest:
+		return MessageDigestEmailData{
+			Total: 4,
+			Rooms: []DigestRoom{
+				{SenderName: "ada_lovelace", Count: 3, LastMessage: "Pushed the fix, can you review?"},
+				{SenderName: "linus", Count: 1, LastMessage: "Thanks!"},
+			},
+			URL: AppURL("/chat"),
+		}, true
+	case EmailMagicLink:
+		return MagicLinkEmailData{URL: AppURL("/magic?token=sample"), ValidMinutes: 15}, true
+	}
+	return nil, false
 }
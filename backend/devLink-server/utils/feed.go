@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	MaxFollowedTags = 50
+	MaxTagLength    = 32
+)
+
+// NormalizeTag lowercases a tag and drops a leading # so followed tags and
+// post tags compare equal. It returns "" for tags that are unusable.
+func NormalizeTag(tag string) string {
+	tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+	if tag == "" || len(tag) > MaxTagLength {
+		return ""
+	}
+	return tag
+}
+
+// NormalizeTags normalizes and de-duplicates a post's tags, keeping their
+// order.
+func NormalizeTags(tags []string) []string {
+	out := []string{}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		if tag = NormalizeTag(tag); tag != "" && !seen[tag] {
+			seen[tag] = true
+			out = append(out, tag)
+		}
+	}
+	return out
+}
+
+// EnsureFeedIndexes backs the feed's candidate queries so that building a
+// page never scans the whole posts collection.
+func EnsureFeedIndexes(ctx context.Context, client *mongo.Client) error {
+	_, err := database.OpenCollection("posts", client).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "published", Value: 1}, {Key: "author_id", Value: 1}, {Key: "published_at", Value: -1}}},
+		{Keys: bson.D{{Key: "published", Value: 1}, {Key: "tags", Value: 1}, {Key: "published_at", Value: -1}}},
+		{Keys: bson.D{{Key: "published", Value: 1}, {Key: "trending_score", Value: -1}, {Key: "_id", Value: -1}}},
+	})
+	return err
+}
+
+// A trending score adds the log of a post's views to its publication time,
+// so ten times the views are worth TrendingViewStep of recency. The score
+// moves with views but not with the clock, and it is only stored by
+// RefreshTrendingScores, so the order a reader pages through holds between
+// refreshes.
+const (
+	TrendingViewStep = 12 * time.Hour
+	TrendingWindow   = 30 * 24 * time.Hour
+)
+
+// RefreshTrendingScores stores the trending score of the posts published
+// within TrendingWindow of now. Older posts have no views left to gain
+// ground with and keep their last score.
+func RefreshTrendingScores(ctx context.Context, client *mongo.Client, now time.Time) error {
+	_, err := database.OpenCollection("posts", client).UpdateMany(
+		ctx,
+		bson.M{"published": true, "published_at": bson.M{"$gte": now.Add(-TrendingWindow)}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{
+			"trending_score": bson.M{"$add": bson.A{
+				bson.M{"$log10": bson.M{"$max": bson.A{"$view_count", 1}}},
+				bson.M{"$divide": bson.A{bson.M{"$toLong": "$published_at"}, TrendingViewStep.Milliseconds()}},
+			}},
+		}}}},
+	)
+	return err
+}
+
+// BackfillPostTags normalizes the tags of posts written before tags were
+// normalized, so that followed tags match them.
+func BackfillPostTags(ctx context.Context, client *mongo.Client) error {
+	postCollection := database.OpenCollection("posts", client)
+
+	cursor, err := postCollection.Find(
+		ctx,
+		bson.M{"tags": bson.M{"$regex": `^#|^\s|\s$|\p{Lu}`}},
+		options.Find().SetProjection(bson.M{"tags": 1}),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var post models.Post
+		if err := cursor.Decode(&post); err != nil {
+			continue
+		}
+
+		tags := NormalizeTags(post.Tags)
+		if slices.Equal(tags, post.Tags) {
+			continue
+		}
+
+		if _, err := postCollection.UpdateOne(ctx, bson.M{"_id": post.ID}, bson.M{"$set": bson.M{"tags": tags}}); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
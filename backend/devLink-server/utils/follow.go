@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// EnsureFollowIndexes makes follows unique per pair and backs both list
+// directions, newest first.
+func EnsureFollowIndexes(ctx context.Context, client *mongo.Client) error {
+	_, err := database.OpenCollection("follows", client).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "follower_id", Value: 1}, {Key: "followee_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "followee_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "follower_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	return err
+}
+
+// FollowRelation describes the graph between the viewer and another user.
+type FollowRelation struct {
+	Following  bool `json:"is_following"`
+	FollowedBy bool `json:"follows_you"`
+}
+
+func (r FollowRelation) Mutual() bool {
+	return r.Following && r.FollowedBy
+}
+
+// GetFollowRelation looks up both directions between viewer and other in
+// one query.
+func GetFollowRelation(ctx context.Context, client *mongo.Client, viewer, other bson.ObjectID) (FollowRelation, error) {
+	var rel FollowRelation
+
+	cursor, err := database.OpenCollection("follows", client).Find(ctx, bson.M{"$or": []bson.M{
+		{"follower_id": viewer, "followee_id": other},
+		{"follower_id": other, "followee_id": viewer},
+	}}, options.Find().SetProjection(bson.M{"follower_id": 1}))
+	if err != nil {
+		return rel, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var edge struct {
+			FollowerID bson.ObjectID `bson:"follower_id"`
+		}
+		if err := cursor.Decode(&edge); err != nil {
+			return rel, err
+		}
+		if edge.FollowerID == viewer {
+			rel.Following = true
+		} else {
+			rel.FollowedBy = true
+		}
+	}
+	return rel, cursor.Err()
+}
+
+// FollowCounts returns how many users follow userID and how many it
+// follows.
+func FollowCounts(ctx context.Context, client *mongo.Client, userID bson.ObjectID) (followers, following int64, err error) {
+	followCollection := database.OpenCollection("follows", client)
+
+	if followers, err = followCollection.CountDocuments(ctx, bson.M{"followee_id": userID}); err != nil {
+		return 0, 0, err
+	}
+	following, err = followCollection.CountDocuments(ctx, bson.M{"follower_id": userID})
+	return followers, following, err
+}
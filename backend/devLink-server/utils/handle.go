@@ -0,0 +1,303 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	HandleMinLength = 3
+	HandleMaxLength = 30
+
+	// A released handle keeps redirecting to its previous owner and cannot
+	// be claimed by anyone else for this long.
+	HandleHoldPeriod = 90 * 24 * time.Hour
+
+	// Renames are spaced out so that one account cannot hold many handles
+	// at once through their hold periods.
+	HandleChangeInterval = 30 * 24 * time.Hour
+)
+
+var (
+	ErrHandleInvalid  = errors.New("handle must be 3-30 characters of lowercase letters, digits and underscores, starting with a letter")
+	ErrHandleReserved = errors.New("handle is reserved")
+	ErrHandleTaken    = errors.New("handle is already taken")
+	ErrHandleTooSoon  = errors.New("handle can only be changed once every 30 days")
+)
+
+var handlePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{2,29}$`)
+
+// Route segments, product names and role-like words nobody should own.
+var reservedHandles = map[string]bool{
+	"about": true, "account": true, "admin": true, "administrator": true,
+	"anonymous": true, "api": true, "archive": true, "assets": true,
+	"auth": true, "blog": true, "chat": true, "contact": true,
+	"dashboard": true, "deleted": true, "devlink": true, "docs": true,
+	"edit": true, "email": true, "everyone": true, "explore": true,
+	"feed": true, "help": true, "here": true, "home": true,
+	"jwks": true, "login": true, "logout": true, "magic": true,
+	"mail": true, "me": true, "messages": true, "mod": true,
+	"moderator": true, "new": true, "notifications": true, "null": true,
+	"oauth": true, "official": true, "posts": true, "privacy": true,
+	"profile": true, "register": true, "root": true, "search": true,
+	"security": true, "settings": true, "signin": true, "signup": true,
+	"staff": true, "static": true, "status": true, "support": true,
+	"system": true, "terms": true, "trending": true, "undefined": true,
+	"user": true, "users": true, "verify": true, "www": true,
+}
+
+// NormalizeHandle lowercases a handle and drops a leading @ so that lookups
+// and uniqueness are case-insensitive.
+func NormalizeHandle(handle string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(handle), "@"))
+}
+
+// ValidateHandle checks a normalized handle against the format rules and the
+// reserved list.
+func ValidateHandle(handle string) error {
+	if !handlePattern.MatchString(handle) {
+		return ErrHandleInvalid
+	}
+	if reservedHandles[handle] || strings.Contains(handle, "devlink") {
+		return ErrHandleReserved
+	}
+	return nil
+}
+
+// EnsureHandleIndexes enforces handle uniqueness. Users created before
+// handles existed have none, hence the partial filter.
+func EnsureHandleIndexes(ctx context.Context, client *mongo.Client) error {
+	if _, err := database.OpenCollection("users", client).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "handle", Value: 1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"handle": bson.M{"$type": "string"}}),
+	}); err != nil {
+		return err
+	}
+
+	_, err := database.OpenCollection("handle_history", client).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "handle", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+	})
+	return err
+}
+
+// CheckHandleAvailable reports whether userID may take handle. Pass a zero
+// id for a user that does not exist yet.
+func CheckHandleAvailable(ctx context.Context, client *mongo.Client, handle string, userID bson.ObjectID) error {
+	if err := ValidateHandle(handle); err != nil {
+		return err
+	}
+
+	err := database.OpenCollection("users", client).FindOne(
+		ctx,
+		bson.M{"handle": handle, "_id": bson.M{"$ne": userID}},
+		options.FindOne().SetProjection(bson.M{"_id": 1}),
+	).Err()
+	if err == nil {
+		return ErrHandleTaken
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+
+	var held models.HandleHistory
+	err = database.OpenCollection("handle_history", client).FindOne(ctx, bson.M{"handle": handle}).Decode(&held)
+	if err == nil && held.UserID != userID && time.Since(held.ReleasedAt) < HandleHoldPeriod {
+		return ErrHandleTaken
+	}
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+	return nil
+}
+
+// ChangeHandle moves the user to a new handle and keeps the old one in the
+// history so that links to it redirect.
+func ChangeHandle(ctx context.Context, client *mongo.Client, userID bson.ObjectID, oldHandle, newHandle string) error {
+	if err := CheckHandleAvailable(ctx, client, newHandle, userID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	filter := bson.M{"_id": userID}
+	if oldHandle != "" {
+		// Choosing a first handle is free; renaming waits out the interval.
+		filter["$or"] = bson.A{
+			bson.M{"handle_changed_at": bson.M{"$exists": false}},
+			bson.M{"handle_changed_at": bson.M{"$lte": now.Add(-HandleChangeInterval)}},
+		}
+	}
+
+	result, err := database.OpenCollection("users", client).UpdateOne(ctx, filter, bson.M{
+		"$set": bson.M{
+			"handle":            newHandle,
+			"handle_changed_at": now,
+			"updated_at":        now,
+		},
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrHandleTaken
+	}
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrHandleTooSoon
+	}
+
+	historyCollection := database.OpenCollection("handle_history", client)
+
+	// Claiming a handle ends its redirect, whether the previous owner takes
+	// it back or its hold period is over.
+	if _, err := historyCollection.DeleteOne(ctx, bson.M{"handle": newHandle}); err != nil {
+		return err
+	}
+
+	if oldHandle == "" || oldHandle == newHandle {
+		return nil
+	}
+
+	_, err = historyCollection.UpdateOne(
+		ctx,
+		bson.M{"handle": oldHandle},
+		bson.M{"$set": bson.M{"user_id": userID, "released_at": now}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
+
+// ResolveHandle finds the user currently holding handle. If the handle was
+// released by a rename, the user is returned with moved set so callers can
+// redirect to the current handle.
+func ResolveHandle(ctx context.Context, client *mongo.Client, handle string) (user models.User, moved bool, err error) {
+	userCollection := database.OpenCollection("users", client)
+
+	err = userCollection.FindOne(ctx, bson.M{
+		"handle":                handle,
+		"deletion_requested_at": bson.M{"$exists": false},
+	}).Decode(&user)
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return user, false, err
+	}
+
+	var held models.HandleHistory
+	if err := database.OpenCollection("handle_history", client).FindOne(ctx, bson.M{"handle": handle}).Decode(&held); err != nil {
+		return user, false, err
+	}
+
+	err = userCollection.FindOne(ctx, bson.M{
+		"_id":                   held.UserID,
+		"handle":                bson.M{"$type": "string"},
+		"deletion_requested_at": bson.M{"$exists": false},
+	}).Decode(&user)
+	return user, err == nil, err
+}
+
+// GenerateHandle derives a free handle from a display name or email, adding
+// a random suffix when the plain form is taken.
+func GenerateHandle(ctx context.Context, client *mongo.Client, seed string) (string, error) {
+	if at := strings.Index(seed, "@"); at > 0 {
+		seed = seed[:at]
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(seed) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '_' || r == '-' || r == '.' || r == ' ':
+			if b.Len() > 0 && !strings.HasSuffix(b.String(), "_") {
+				b.WriteByte('_')
+			}
+		}
+	}
+
+	base := strings.TrimLeft(strings.Trim(b.String(), "_"), "0123456789_")
+	if len(base) < HandleMinLength {
+		base = "dev" + base
+	}
+	if errors.Is(ValidateHandle(base), ErrHandleReserved) {
+		base = "dev_" + strings.ReplaceAll(base, "devlink", "dl")
+	}
+	// Leave room for the collision suffix.
+	if len(base) > HandleMaxLength-5 {
+		base = strings.TrimRight(base[:HandleMaxLength-5], "_")
+	}
+
+	candidate := base
+	for range 10 {
+		err := CheckHandleAvailable(ctx, client, candidate, bson.ObjectID{})
+		if err == nil {
+			return candidate, nil
+		}
+		if !errors.Is(err, ErrHandleTaken) && !errors.Is(err, ErrHandleReserved) {
+			return "", err
+		}
+
+		n, err := rand.Int(rand.Reader, big.NewInt(10000))
+		if err != nil {
+			return "", err
+		}
+		candidate = base + "_" + n.String()
+	}
+	return "", ErrHandleTaken
+}
+
+// BackfillHandles gives every user created before handles existed one
+// derived from their name.
+func BackfillHandles(ctx context.Context, client *mongo.Client) error {
+	userCollection := database.OpenCollection("users", client)
+
+	cursor, err := userCollection.Find(
+		ctx,
+		bson.M{"handle": bson.M{"$exists": false}},
+		options.Find().SetProjection(bson.M{"name": 1, "email": 1}),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var user models.User
+		if err := cursor.Decode(&user); err != nil {
+			continue
+		}
+
+		seed := user.UserName
+		if seed == "" {
+			seed = user.Email
+		}
+
+		handle, err := GenerateHandle(ctx, client, seed)
+		if err != nil {
+			return err
+		}
+
+		_, err = userCollection.UpdateOne(
+			ctx,
+			bson.M{"_id": user.Id, "handle": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"handle": handle}},
+		)
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+	}
+	return cursor.Err()
+}
@@ -0,0 +1,441 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultKeyID names the key built from JWT_SECRET when no key set is
+// configured. Tokens without a kid header, issued before key rotation was
+// introduced, are verified against it.
+const DefaultKeyID = "default"
+
+const defaultKeyGrace = time.Hour
+
+var ErrUnknownSigningKey = errors.New("unknown or retired signing key")
+
+// SigningKey is one entry of the key set. HS256 keys use Secret, RS256 and
+// EdDSA keys use Private to sign and Public to verify. A key with only
+// Public set can verify tokens but never sign them.
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	Secret    []byte
+	Private   crypto.Signer
+	Public    crypto.PublicKey
+	// RetiredAt marks a key that no longer signs. Its tokens keep verifying
+	// for the manager's grace period afterwards.
+	RetiredAt time.Time
+}
+
+func (k *SigningKey) method() jwt.SigningMethod {
+	switch k.Algorithm {
+	case "HS256":
+		return jwt.SigningMethodHS256
+	case "RS256":
+		return jwt.SigningMethodRS256
+	case "EdDSA":
+		return jwt.SigningMethodEdDSA
+	}
+	return nil
+}
+
+func (k *SigningKey) signingKey() any {
+	if k.Algorithm == "HS256" {
+		return k.Secret
+	}
+	return k.Private
+}
+
+func (k *SigningKey) verificationKey() any {
+	if k.Algorithm == "HS256" {
+		return k.Secret
+	}
+	return k.Public
+}
+
+func (k *SigningKey) validate() error {
+	if k.ID == "" {
+		return errors.New("signing key without kid")
+	}
+
+	switch k.Algorithm {
+	case "HS256":
+		if len(k.Secret) == 0 {
+			return fmt.Errorf("key %q: HS256 secret is empty", k.ID)
+		}
+	case "RS256":
+		if k.Private != nil {
+			if _, ok := k.Private.(*rsa.PrivateKey); !ok {
+				return fmt.Errorf("key %q: RS256 needs an RSA private key", k.ID)
+			}
+		}
+		if _, ok := k.Public.(*rsa.PublicKey); !ok {
+			return fmt.Errorf("key %q: RS256 needs an RSA public key", k.ID)
+		}
+	case "EdDSA":
+		if k.Private != nil {
+			if _, ok := k.Private.(ed25519.PrivateKey); !ok {
+				return fmt.Errorf("key %q: EdDSA needs an Ed25519 private key", k.ID)
+			}
+		}
+		if _, ok := k.Public.(ed25519.PublicKey); !ok {
+			return fmt.Errorf("key %q: EdDSA needs an Ed25519 public key", k.ID)
+		}
+	default:
+		return fmt.Errorf("key %q: unsupported algorithm %q", k.ID, k.Algorithm)
+	}
+	return nil
+}
+
+// KeyManager holds every key that may verify tokens and the single active
+// key used to sign new ones.
+type KeyManager struct {
+	mu     sync.RWMutex
+	keys   map[string]*SigningKey
+	active string
+	grace  time.Duration
+	now    func() time.Time
+}
+
+func NewKeyManager(grace time.Duration, now func() time.Time) *KeyManager {
+	return &KeyManager{
+		keys:  make(map[string]*SigningKey),
+		grace: grace,
+		now:   now,
+	}
+}
+
+// AddKey registers a key. The first key able to sign becomes active unless
+// another one is activated explicitly.
+func (m *KeyManager) AddKey(key SigningKey) error {
+	if err := key.validate(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.keys[key.ID]; exists {
+		return fmt.Errorf("duplicate kid %q", key.ID)
+	}
+
+	m.keys[key.ID] = &key
+	if m.active == "" && key.RetiredAt.IsZero() && key.signingKey() != nil {
+		m.active = key.ID
+	}
+	return nil
+}
+
+// Activate makes kid the signing key. The previously active key is retired
+// and keeps verifying for the grace period, so sessions survive rotation.
+func (m *KeyManager) Activate(kid string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key, ok := m.keys[kid]
+	if !ok {
+		return fmt.Errorf("unknown kid %q", kid)
+	}
+	if key.Algorithm != "HS256" && key.Private == nil {
+		return fmt.Errorf("key %q has no private key", kid)
+	}
+
+	if prev, ok := m.keys[m.active]; ok && prev.ID != kid {
+		prev.RetiredAt = m.now()
+	}
+
+	key.RetiredAt = time.Time{}
+	m.active = kid
+	return nil
+}
+
+func (m *KeyManager) usable(key *SigningKey) bool {
+	return key.RetiredAt.IsZero() || m.now().Before(key.RetiredAt.Add(m.grace))
+}
+
+// Sign signs claims with the active key and sets its kid header.
+func (m *KeyManager) Sign(claims jwt.Claims) (string, error) {
+	m.mu.RLock()
+	key, ok := m.keys[m.active]
+	m.mu.RUnlock()
+
+	if !ok {
+		return "", errors.New("no active signing key")
+	}
+
+	token := jwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.ID
+
+	return token.SignedString(key.signingKey())
+}
+
+// Parse verifies tokenStr with the key named by its kid header, rejecting
+// tokens whose algorithm does not match that key.
+func (m *KeyManager) Parse(tokenStr string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenStr, claims, m.keyFunc)
+}
+
+func (m *KeyManager) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		kid = DefaultKeyID
+	}
+
+	m.mu.RLock()
+	key, ok := m.keys[kid]
+	usable := ok && m.usable(key)
+	m.mu.RUnlock()
+
+	if !usable {
+		return nil, ErrUnknownSigningKey
+	}
+
+	if token.Method.Alg() != key.Algorithm {
+		return nil, jwt.ErrTokenSignatureInvalid
+	}
+
+	return key.verificationKey(), nil
+}
+
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+// JWKS lists the public halves of the asymmetric keys that can currently
+// verify tokens. HS256 secrets are never published.
+func (m *KeyManager) JWKS() []JWK {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	jwks := []JWK{}
+	for _, key := range m.keys {
+		if !m.usable(key) {
+			continue
+		}
+
+		switch pub := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwks = append(jwks, JWK{
+				KeyType:   "RSA",
+				KeyID:     key.ID,
+				Use:       "sig",
+				Algorithm: key.Algorithm,
+				N:         base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks = append(jwks, JWK{
+				KeyType:   "OKP",
+				KeyID:     key.ID,
+				Use:       "sig",
+				Algorithm: key.Algorithm,
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+
+	sort.Slice(jwks, func(i, j int) bool { return jwks[i].KeyID < jwks[j].KeyID })
+	return jwks
+}
+
+type keySetConfig struct {
+	Active string            `json:"active"`
+	Grace  string            `json:"grace"`
+	Keys   []signingKeyEntry `json:"keys"`
+}
+
+type signingKeyEntry struct {
+	ID             string    `json:"kid"`
+	Algorithm      string    `json:"alg"`
+	Secret         string    `json:"secret"`
+	SecretEnv      string    `json:"secret_env"`
+	PrivateKey     string    `json:"private_key"`
+	PrivateKeyFile string    `json:"private_key_file"`
+	PublicKey      string    `json:"public_key"`
+	PublicKeyFile  string    `json:"public_key_file"`
+	RetiredAt      time.Time `json:"retired_at"`
+}
+
+// LoadKeyManager builds the key set from JWT_KEYS (inline JSON) or
+// JWT_KEYS_FILE. Without either it falls back to a single HS256 key from
+// JWT_SECRET with kid "default". The set is read once at startup, so keys
+// are rotated by editing it and restarting.
+func LoadKeyManager() (*KeyManager, error) {
+	raw := []byte(os.Getenv("JWT_KEYS"))
+	if path := os.Getenv("JWT_KEYS_FILE"); len(raw) == 0 && path != "" {
+		var err error
+		if raw, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(raw) == 0 {
+		m := NewKeyManager(defaultKeyGrace, time.Now)
+		err := m.AddKey(SigningKey{
+			ID:        DefaultKeyID,
+			Algorithm: "HS256",
+			Secret:    []byte(os.Getenv("JWT_SECRET")),
+		})
+		return m, err
+	}
+
+	var cfg keySetConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("parse key set: %w", err)
+	}
+
+	grace := defaultKeyGrace
+	if cfg.Grace != "" {
+		d, err := time.ParseDuration(cfg.Grace)
+		if err != nil {
+			return nil, fmt.Errorf("parse key set grace: %w", err)
+		}
+		grace = d
+	}
+
+	m := NewKeyManager(grace, time.Now)
+	for _, entry := range cfg.Keys {
+		key, err := entry.load()
+		if err != nil {
+			return nil, err
+		}
+		if err := m.AddKey(key); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Active != "" {
+		m.active = ""
+		if err := m.Activate(cfg.Active); err != nil {
+			return nil, err
+		}
+	}
+	if m.active == "" {
+		return nil, errors.New("key set has no key able to sign")
+	}
+
+	return m, nil
+}
+
+func (e signingKeyEntry) load() (SigningKey, error) {
+	key := SigningKey{ID: e.ID, Algorithm: e.Algorithm, RetiredAt: e.RetiredAt}
+
+	if e.Algorithm == "HS256" {
+		secret := e.Secret
+		if e.SecretEnv != "" {
+			secret = os.Getenv(e.SecretEnv)
+		}
+		key.Secret = []byte(secret)
+		return key, nil
+	}
+
+	privatePEM, err := readPEMSource(e.PrivateKey, e.PrivateKeyFile)
+	if err != nil {
+		return key, fmt.Errorf("key %q: %w", e.ID, err)
+	}
+	if privatePEM != nil {
+		priv, err := parsePrivateKeyPEM(privatePEM)
+		if err != nil {
+			return key, fmt.Errorf("key %q: %w", e.ID, err)
+		}
+		key.Private = priv
+		key.Public = priv.Public()
+		return key, nil
+	}
+
+	publicPEM, err := readPEMSource(e.PublicKey, e.PublicKeyFile)
+	if err != nil {
+		return key, fmt.Errorf("key %q: %w", e.ID, err)
+	}
+	if publicPEM == nil {
+		return key, fmt.Errorf("key %q: private or public key required", e.ID)
+	}
+
+	block, _ := pem.Decode(publicPEM)
+	if block == nil {
+		return key, fmt.Errorf("key %q: invalid public key PEM", e.ID)
+	}
+	if key.Public, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+		return key, fmt.Errorf("key %q: %w", e.ID, err)
+	}
+	return key, nil
+}
+
+func readPEMSource(inline, path string) ([]byte, error) {
+	if inline != "" {
+		return []byte(inline), nil
+	}
+	if path != "" {
+		return os.ReadFile(path)
+	}
+	return nil, nil
+}
+
+func parsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid private key PEM")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		if signer, ok := key.(crypto.Signer); ok {
+			return signer, nil
+		}
+		return nil, errors.New("unsupported private key type")
+	}
+
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
+
+var (
+	keyManagerMu sync.Mutex
+	keyManager   *KeyManager
+)
+
+// Keys returns the process-wide key manager, loading it from the
+// environment on first use.
+func Keys() *KeyManager {
+	keyManagerMu.Lock()
+	defer keyManagerMu.Unlock()
+
+	if keyManager == nil {
+		m, err := LoadKeyManager()
+		if err != nil {
+			log.Fatal("JWT key setup failed: ", err)
+		}
+		keyManager = m
+	}
+	return keyManager
+}
+
+// SetKeys replaces the process-wide key manager.
+func SetKeys(m *KeyManager) {
+	keyManagerMu.Lock()
+	defer keyManagerMu.Unlock()
+
+	keyManager = m
+}
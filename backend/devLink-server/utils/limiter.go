@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// LockoutPolicy describes exponential lockout: once Threshold failures have
+// been recorded within Window, every further failure doubles the lockout
+// starting at BaseLockout, capped at MaxLockout.
+type LockoutPolicy struct {
+	Threshold   int
+	BaseLockout time.Duration
+	MaxLockout  time.Duration
+	Window      time.Duration
+}
+
+var (
+	AccountLockoutPolicy = LockoutPolicy{
+		Threshold:   5,
+		BaseLockout: 30 * time.Second,
+		MaxLockout:  1 * time.Hour,
+		Window:      24 * time.Hour,
+	}
+
+	IPLockoutPolicy = LockoutPolicy{
+		Threshold:   20,
+		BaseLockout: 1 * time.Minute,
+		MaxLockout:  1 * time.Hour,
+		Window:      1 * time.Hour,
+	}
+)
+
+func (p LockoutPolicy) lockout(failures int) time.Duration {
+	if failures < p.Threshold {
+		return 0
+	}
+
+	d := p.BaseLockout
+	for i := p.Threshold; i < failures && d < p.MaxLockout; i++ {
+		d *= 2
+	}
+	if d > p.MaxLockout {
+		d = p.MaxLockout
+	}
+	return d
+}
+
+// AttemptLimiter counts failed attempts per key and locks the key out once
+// its policy threshold is reached.
+type AttemptLimiter interface {
+	// Check returns how long key remains locked out, zero when allowed.
+	Check(ctx context.Context, key string) (time.Duration, error)
+	// Fail records a failed attempt and returns the resulting lockout.
+	Fail(ctx context.Context, key string) (time.Duration, error)
+	// Reset clears the failures recorded for key.
+	Reset(ctx context.Context, key string) error
+}
+
+// NewAttemptLimiter returns a Mongo-backed limiter when LIMITER_BACKEND is
+// "mongo", so that counters are shared between instances, and an in-memory
+// one otherwise.
+func NewAttemptLimiter(client *mongo.Client, namespace string, policy LockoutPolicy) AttemptLimiter {
+	if os.Getenv("LIMITER_BACKEND") == "mongo" && client != nil {
+		return NewMongoLimiter(client, namespace, policy)
+	}
+	return NewMemoryLimiter(policy, time.Now)
+}
+
+const maxMemoryEntries = 10000
+
+type attemptState struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+type MemoryLimiter struct {
+	policy LockoutPolicy
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*attemptState
+}
+
+func NewMemoryLimiter(policy LockoutPolicy, now func() time.Time) *MemoryLimiter {
+	return &MemoryLimiter{
+		policy:  policy,
+		now:     now,
+		entries: make(map[string]*attemptState),
+	}
+}
+
+func (l *MemoryLimiter) Check(ctx context.Context, key string) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state, ok := l.entries[key]
+	if !ok {
+		return 0, nil
+	}
+
+	now := l.now()
+	if now.Sub(state.lastFailure) > l.policy.Window {
+		delete(l.entries, key)
+		return 0, nil
+	}
+
+	if now.Before(state.lockedUntil) {
+		return state.lockedUntil.Sub(now), nil
+	}
+	return 0, nil
+}
+
+func (l *MemoryLimiter) Fail(ctx context.Context, key string) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	state, ok := l.entries[key]
+	if !ok || now.Sub(state.lastFailure) > l.policy.Window {
+		state = &attemptState{}
+		l.entries[key] = state
+	}
+
+	state.failures++
+	state.lastFailure = now
+
+	lockout := l.policy.lockout(state.failures)
+	state.lockedUntil = now.Add(lockout)
+
+	if len(l.entries) > maxMemoryEntries {
+		l.sweep(now)
+	}
+
+	return lockout, nil
+}
+
+func (l *MemoryLimiter) Reset(ctx context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.entries, key)
+	return nil
+}
+
+func (l *MemoryLimiter) sweep(now time.Time) {
+	for key, state := range l.entries {
+		if now.Sub(state.lastFailure) > l.policy.Window {
+			delete(l.entries, key)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type MongoLimiter struct {
+	col       *mongo.Collection
+	namespace string
+	policy    LockoutPolicy
+	now       func() time.Time
+}
+
+type attemptDoc struct {
+	Failures      int       `bson:"failures"`
+	LastFailureAt time.Time `bson:"last_failure_at"`
+	LockedUntil   time.Time `bson:"locked_until"`
+}
+
+func NewMongoLimiter(client *mongo.Client, namespace string, policy LockoutPolicy) *MongoLimiter {
+	col := database.OpenCollection("auth_attempts", client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	col.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+
+	return &MongoLimiter{
+		col:       col,
+		namespace: namespace,
+		policy:    policy,
+		now:       time.Now,
+	}
+}
+
+func (l *MongoLimiter) id(key string) string {
+	return l.namespace + ":" + key
+}
+
+func (l *MongoLimiter) Check(ctx context.Context, key string) (time.Duration, error) {
+	var doc attemptDoc
+	err := l.col.FindOne(ctx, bson.M{"_id": l.id(key)}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	now := l.now()
+	if now.Before(doc.LockedUntil) {
+		return doc.LockedUntil.Sub(now), nil
+	}
+	return 0, nil
+}
+
+func (l *MongoLimiter) Fail(ctx context.Context, key string) (time.Duration, error) {
+	now := l.now()
+	windowStart := now.Add(-l.policy.Window)
+
+	var doc attemptDoc
+	err := l.col.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": l.id(key)},
+		mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{
+				"failures": bson.M{
+					"$cond": bson.A{
+						bson.M{"$gt": bson.A{"$last_failure_at", windowStart}},
+						bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$failures", 0}}, 1}},
+						1,
+					},
+				},
+				"last_failure_at": now,
+				"expires_at":      now.Add(l.policy.Window),
+			}}},
+		},
+		options.FindOneAndUpdate().
+			SetUpsert(true).
+			SetReturnDocument(options.After),
+	).Decode(&doc)
+	if err != nil {
+		return 0, err
+	}
+
+	lockout := l.policy.lockout(doc.Failures)
+	if lockout == 0 {
+		return 0, nil
+	}
+
+	_, err = l.col.UpdateOne(
+		ctx,
+		bson.M{"_id": l.id(key)},
+		bson.M{"$max": bson.M{"locked_until": now.Add(lockout)}},
+	)
+	return lockout, err
+}
+
+func (l *MongoLimiter) Reset(ctx context.Context, key string) error {
+	_, err := l.col.DeleteOne(ctx, bson.M{"_id": l.id(key)})
+	return err
+}
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const MagicLinkTTL = 15 * time.Minute
+
+var ErrInvalidMagicLink = errors.New("invalid or expired magic link")
+
+type magicLinkDoc struct {
+	ID        string        `bson:"_id"`
+	UserID    bson.ObjectID `bson:"user_id"`
+	CreatedAt time.Time     `bson:"created_at"`
+	ExpiresAt time.Time     `bson:"expires_at"`
+	UsedAt    *time.Time    `bson:"used_at,omitempty"`
+}
+
+// EnsureMagicLinkIndexes lets Mongo drop spent links once they expire.
+func EnsureMagicLinkIndexes(ctx context.Context, client *mongo.Client) error {
+	_, err := database.OpenCollection("magic_links", client).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+	})
+	return err
+}
+
+// LastMagicLinkSentAt reports when the newest magic link for a user was
+// issued, the zero time if there is none.
+func LastMagicLinkSentAt(ctx context.Context, client *mongo.Client, userID bson.ObjectID) time.Time {
+	var doc magicLinkDoc
+	err := database.OpenCollection("magic_links", client).FindOne(
+		ctx,
+		bson.M{"user_id": userID},
+		options.FindOne().SetSort(bson.M{"created_at": -1}),
+	).Decode(&doc)
+	if err != nil {
+		return time.Time{}
+	}
+	return doc.CreatedAt
+}
+
+// IssueMagicLink signs a single-use login token bound to the browser holding
+// nonce and records its id so it can only be redeemed once.
+func IssueMagicLink(ctx context.Context, client *mongo.Client, userID bson.ObjectID, nonce string) (string, error) {
+	jti, err := GenerateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+
+	claims := PurposeClaims{
+		UserID:    userID.Hex(),
+		Type:      "magic",
+		NonceHash: HashToken(nonce),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(now.Add(MagicLinkTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+
+	token, err := signPurposeClaims(claims)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := database.OpenCollection("magic_links", client).InsertOne(ctx, magicLinkDoc{
+		ID:        jti,
+		UserID:    userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(MagicLinkTTL),
+	}); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// RedeemMagicLink checks the signature, expiry and browser nonce of a magic
+// link token and marks it used. A token can be redeemed only once.
+func RedeemMagicLink(ctx context.Context, client *mongo.Client, token, nonce string) (bson.ObjectID, error) {
+	claims, err := VerifyPurposeToken(token, "magic")
+	if err != nil || claims.ID == "" || nonce == "" {
+		return bson.ObjectID{}, ErrInvalidMagicLink
+	}
+
+	if subtle.ConstantTimeCompare([]byte(claims.NonceHash), []byte(HashToken(nonce))) != 1 {
+		return bson.ObjectID{}, ErrInvalidMagicLink
+	}
+
+	userID, err := bson.ObjectIDFromHex(claims.UserID)
+	if err != nil {
+		return bson.ObjectID{}, ErrInvalidMagicLink
+	}
+
+	now := time.Now()
+
+	res, err := database.OpenCollection("magic_links", client).UpdateOne(
+		ctx,
+		bson.M{
+			"_id":        claims.ID,
+			"user_id":    userID,
+			"used_at":    bson.M{"$exists": false},
+			"expires_at": bson.M{"$gt": now},
+		},
+		bson.M{"$set": bson.M{"used_at": now}},
+	)
+	if err != nil {
+		return bson.ObjectID{}, err
+	}
+	if res.ModifiedCount == 0 {
+		return bson.ObjectID{}, ErrInvalidMagicLink
+	}
+
+	return userID, nil
+}
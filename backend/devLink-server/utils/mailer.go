@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+type Email struct {
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Email) error
+}
+
+// NewMailerFromEnv picks the transport named by MAIL_TRANSPORT. When it is
+// unset, Resend is used if RESEND_API_KEY is configured and the log
+// transport otherwise, so the server can run without any mail provider.
+// Production refuses the log and file transports, which would leave OTPs,
+// reset codes and magic links in logs or on disk instead of delivering them.
+func NewMailerFromEnv() (Mailer, error) {
+	transport := strings.ToLower(os.Getenv("MAIL_TRANSPORT"))
+	if transport == "" {
+		transport = "log"
+		if os.Getenv("RESEND_API_KEY") != "" {
+			transport = "resend"
+		}
+	}
+
+	if os.Getenv("ENV") == "production" {
+		switch transport {
+		case "log", "stdout", "file", "maildir":
+			return nil, fmt.Errorf("MAIL_TRANSPORT %q does not deliver mail and is not allowed in production", transport)
+		}
+	}
+
+	from := os.Getenv("EMAIL_FROM")
+
+	switch transport {
+	case "resend":
+		apiKey := os.Getenv("RESEND_API_KEY")
+		if apiKey == "" || from == "" {
+			return nil, fmt.Errorf("resend env variables not set")
+		}
+		return NewResendMailer(apiKey, from), nil
+
+	case "smtp":
+		host := os.Getenv("SMTP_HOST")
+		if host == "" || from == "" {
+			return nil, fmt.Errorf("smtp env variables not set")
+		}
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		return &SMTPMailer{
+			Host:     host,
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		}, nil
+
+	case "file", "maildir":
+		dir := os.Getenv("MAIL_DIR")
+		if dir == "" {
+			dir = "mail"
+		}
+		if from == "" {
+			from = "DevLink <no-reply@localhost>"
+		}
+		return NewFileMailer(dir, from)
+
+	case "log", "stdout":
+		return &LogMailer{Logger: log.New(os.Stdout, "", log.LstdFlags)}, nil
+	}
+
+	return nil, fmt.Errorf("unknown MAIL_TRANSPORT %q", transport)
+}
+
+// LogMailer prints every message instead of delivering it.
+type LogMailer struct {
+	Logger *log.Logger
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Email) error {
+	body := msg.Text
+	if body == "" {
+		body = msg.HTML
+	}
+	m.Logger.Printf("MAIL to=%s subject=%q\n%s", msg.To, msg.Subject, body)
+	return nil
+}
+
+// MemoryMailer keeps sent messages so callers can inspect them. Tests use it
+// to read the codes and links that would have gone out by email.
+type MemoryMailer struct {
+	mu   sync.Mutex
+	sent []Email
+}
+
+func (m *MemoryMailer) Send(ctx context.Context, msg Email) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func (m *MemoryMailer) Sent() []Email {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Email(nil), m.sent...)
+}
+
+func (m *MemoryMailer) Last() (Email, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.sent) == 0 {
+		return Email{}, false
+	}
+	return m.sent[len(m.sent)-1], true
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const resendEndpoint = "https://api.resend.com/emails"
+
+type ResendMailer struct {
+	APIKey   string
+	From     string
+	Endpoint string
+	Client   *http.Client
+}
+
+func NewResendMailer(apiKey, from string) *ResendMailer {
+	return &ResendMailer{
+		APIKey:   apiKey,
+		From:     from,
+		Endpoint: resendEndpoint,
+		Client:   http.DefaultClient,
+	}
+}
+
+func (m *ResendMailer) Send(ctx context.Context, msg Email) error {
+	payload := map[string]interface{}{
+		"from":    m.From,
+		"to":      []string{msg.To},
+		"subject": msg.Subject,
+		"html":    msg.HTML,
+	}
+	if msg.Text != "" {
+		payload["text"] = msg.Text
+	}
+
+	body, _ := json.Marshal(payload)
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		"POST",
+		m.Endpoint,
+		bytes.NewBuffer(body),
+	)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+m.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("resend failed with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,209 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	WordsPerMinute = 200
+
+	// Deeper headings are anchored but left out of the table of contents.
+	maxTOCLevel = 4
+
+	// Heading ids are prefixed so that a post cannot take over ids the page
+	// itself relies on.
+	headingIDPrefix = "user-content-"
+)
+
+// Raw HTML in the source is dropped by the renderer, and the output is
+// sanitized again, so a post can never carry scripts or event handlers.
+var markdownRenderer = goldmark.New(
+	goldmark.WithExtensions(
+		// GFM, with column alignment as attributes since the sanitizer
+		// drops inline styles.
+		extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute)),
+		extension.Strikethrough,
+		extension.Linkify,
+		extension.TaskList,
+	),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+)
+
+var markdownPolicy = func() *bluemonday.Policy {
+	policy := bluemonday.UGCPolicy()
+	policy.AllowAttrs("id").Matching(regexp.MustCompile(`^` + headingIDPrefix + `[\pL\pN_-]+$`)).
+		OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#.-]+$`)).OnElements("code")
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^heading-anchor$`)).OnElements("a")
+	policy.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	policy.AllowAttrs("checked", "disabled").OnElements("input")
+	policy.AllowAttrs("align").Matching(regexp.MustCompile(`^(left|center|right)$`)).OnElements("th", "td")
+	return policy
+}()
+
+// RenderedMarkdown is the sanitized HTML of a post and what is derived from
+// its source.
+type RenderedMarkdown struct {
+	HTML           string
+	TOC            []models.TOCEntry
+	WordCount      int
+	ReadingMinutes int
+}
+
+// RenderMarkdown renders GitHub flavored Markdown. Headings get ids and a
+// self link so that sections can be linked to.
+func RenderMarkdown(source string) (RenderedMarkdown, error) {
+	src := []byte(source)
+	doc := markdownRenderer.Parser().Parse(text.NewReader(src))
+
+	rendered := RenderedMarkdown{TOC: []models.TOCEntry{}}
+	headings := []*ast.Heading{}
+
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch node := n.(type) {
+		case *ast.Heading:
+			headings = append(headings, node)
+		case *ast.Text:
+			rendered.WordCount += len(strings.Fields(string(node.Value(src))))
+		case *ast.String:
+			rendered.WordCount += len(strings.Fields(string(node.Value)))
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			lines := node.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				line := lines.At(i)
+				rendered.WordCount += len(strings.Fields(string(line.Value(src))))
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, heading := range headings {
+		value, ok := heading.AttributeString("id")
+		generated, _ := value.([]byte)
+		if !ok || len(generated) == 0 {
+			continue
+		}
+		id := append([]byte(headingIDPrefix), generated...)
+		heading.SetAttributeString("id", id)
+
+		if heading.Level <= maxTOCLevel {
+			rendered.TOC = append(rendered.TOC, models.TOCEntry{
+				Level: heading.Level,
+				ID:    string(id),
+				Text:  strings.Join(strings.Fields(inlineText(heading, src)), " "),
+			})
+		}
+
+		anchor := ast.NewLink()
+		anchor.Destination = append([]byte("#"), id...)
+		anchor.SetAttributeString("class", []byte("heading-anchor"))
+		anchor.AppendChild(anchor, ast.NewString([]byte("#")))
+		heading.AppendChild(heading, anchor)
+	}
+
+	var buf bytes.Buffer
+	if err := markdownRenderer.Renderer().Render(&buf, src, doc); err != nil {
+		return rendered, err
+	}
+
+	rendered.HTML = markdownPolicy.Sanitize(buf.String())
+	if rendered.WordCount > 0 {
+		rendered.ReadingMinutes = int(math.Ceil(float64(rendered.WordCount) / WordsPerMinute))
+	}
+	return rendered, nil
+}
+
+// inlineText concatenates the text below n, as shown to a reader.
+func inlineText(n ast.Node, src []byte) string {
+	var b strings.Builder
+	ast.Walk(n, func(child ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node := child.(type) {
+		case *ast.Text:
+			b.Write(node.Value(src))
+			if node.SoftLineBreak() || node.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(node.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return b.String()
+}
+
+// Apply stores the rendering on a post about to be inserted.
+func (r RenderedMarkdown) Apply(post *models.Post) {
+	post.ContentHTML = r.HTML
+	post.TOC = r.TOC
+	post.WordCount = r.WordCount
+	post.ReadingMinutes = r.ReadingMinutes
+}
+
+// Fields is the rendering as a $set document.
+func (r RenderedMarkdown) Fields() bson.M {
+	return bson.M{
+		"content_html":    r.HTML,
+		"toc":             r.TOC,
+		"word_count":      r.WordCount,
+		"reading_minutes": r.ReadingMinutes,
+	}
+}
+
+// BackfillRenderedPosts renders posts written before rendering happened on
+// save, and again those whose heading ids predate the prefix.
+func BackfillRenderedPosts(ctx context.Context, client *mongo.Client) error {
+	postCollection := database.OpenCollection("posts", client)
+
+	cursor, err := postCollection.Find(
+		ctx,
+		bson.M{"$or": []bson.M{
+			{"content_html": bson.M{"$exists": false}},
+			{"content_html": bson.M{"$regex": `<h[1-6] id="(?!` + headingIDPrefix + `)`}},
+		}},
+		options.Find().SetProjection(bson.M{"content": 1}),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var post models.Post
+		if err := cursor.Decode(&post); err != nil {
+			continue
+		}
+
+		rendered, err := RenderMarkdown(post.Content)
+		if err != nil {
+			return err
+		}
+
+		if _, err := postCollection.UpdateOne(ctx, bson.M{"_id": post.ID}, bson.M{"$set": rendered.Fields()}); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
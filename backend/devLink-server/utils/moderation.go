@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"context"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	StatusSuspended = "suspended"
+	StatusBanned    = "banned"
+)
+
+// ActiveRestriction returns r unless it has expired.
+func ActiveRestriction(r *models.AccountRestriction, now time.Time) *models.AccountRestriction {
+	if r == nil || (r.Until != nil && !now.Before(*r.Until)) {
+		return nil
+	}
+	return r
+}
+
+// UserRestriction loads the restriction currently in force for a user, nil
+// when the account is in good standing.
+func UserRestriction(ctx context.Context, client *mongo.Client, userID bson.ObjectID) (*models.AccountRestriction, error) {
+	var user models.User
+	err := database.OpenCollection("users", client).FindOne(
+		ctx,
+		bson.M{"_id": userID},
+		options.FindOne().SetProjection(bson.M{"restriction": 1}),
+	).Decode(&user)
+	if err != nil {
+		return nil, err
+	}
+	return ActiveRestriction(user.Restriction, time.Now()), nil
+}
+
+// RestrictionResponse is the error body sent to a restricted user.
+func RestrictionResponse(r *models.AccountRestriction) map[string]any {
+	body := map[string]any{
+		"error":  "Your account has been " + r.Status,
+		"code":   "ACCOUNT_SUSPENDED",
+		"reason": r.Reason,
+	}
+	if r.Status == StatusBanned {
+		body["code"] = "ACCOUNT_BANNED"
+	}
+	if r.Until != nil {
+		body["until"] = r.Until
+	}
+	return body
+}
+
+func activeBanFilter(now time.Time) bson.M {
+	return bson.M{
+		"restriction.status": StatusBanned,
+		"$or": []bson.M{
+			{"restriction.until": bson.M{"$exists": false}},
+			{"restriction.until": bson.M{"$gt": now}},
+		},
+	}
+}
+
+// HiddenAuthorIDs lists the users whose content is hidden from public
+// listings: those with a ban in force and those awaiting account deletion.
+func HiddenAuthorIDs(ctx context.Context, client *mongo.Client) ([]bson.ObjectID, error) {
+	cursor, err := database.OpenCollection("users", client).Find(
+		ctx,
+		bson.M{"$or": []bson.M{
+			activeBanFilter(time.Now()),
+			{"deletion_requested_at": bson.M{"$exists": true}},
+		}},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	ids := []bson.ObjectID{}
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID bson.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err == nil {
+			ids = append(ids, doc.ID)
+		}
+	}
+	return ids, cursor.Err()
+}
+
+// EnsureModerationIndexes keeps the hidden-author lookup off a collection
+// scan.
+func EnsureModerationIndexes(ctx context.Context, client *mongo.Client) error {
+	_, err := database.OpenCollection("users", client).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "restriction.status", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "deletion_requested_at", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+	})
+	return err
+}
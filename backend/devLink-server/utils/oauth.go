@@ -0,0 +1,194 @@
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var ErrOAuthExchange = errors.New("oauth code exchange failed")
+
+// EnsureOAuthIndexes makes a provider account link to at most one user.
+func EnsureOAuthIndexes(ctx context.Context, client *mongo.Client) error {
+	_, err := database.OpenCollection("oauth_identities", client).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "provider", Value: 1}, {Key: "subject", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// OAuthIdentity is the account information a provider vouches for after a
+// successful authorization-code exchange.
+type OAuthIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Username      string
+	AvatarURL     string
+}
+
+// OAuthProvider drives the authorization-code + PKCE flow against a single
+// identity provider.
+type OAuthProvider interface {
+	Name() string
+	AuthCodeURL(state, codeChallenge string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier string) (OAuthIdentity, error)
+}
+
+// OAuthEndpoints holds the client registration and endpoint URLs shared by
+// every provider. All URLs are configurable so the flow can be pointed at a
+// local fake identity provider.
+type OAuthEndpoints struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	AuthURL      string
+	TokenURL     string
+	Scopes       []string
+}
+
+var oauthHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// OAuthProvidersFromEnv returns every provider whose client id is configured.
+func OAuthProvidersFromEnv() map[string]OAuthProvider {
+	providers := make(map[string]OAuthProvider)
+
+	if p := NewGitHubProviderFromEnv(); p != nil {
+		providers[p.Name()] = p
+	}
+	if p := NewOIDCProviderFromEnv(); p != nil {
+		providers[p.Name()] = p
+	}
+
+	return providers
+}
+
+// PKCEChallenge derives the S256 code challenge for a code verifier.
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// OAuthCallbackURL is the default redirect URI registered with a provider.
+func OAuthCallbackURL(provider string) string {
+	base := os.Getenv("API_URL")
+	if base == "" {
+		base = "http://localhost:8080"
+	}
+	return strings.TrimRight(base, "/") + "/api/auth/oauth/" + provider + "/callback"
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func (e OAuthEndpoints) authCodeURL(state, codeChallenge string, extra url.Values) (string, error) {
+	u, err := url.Parse(e.AuthURL)
+	if err != nil {
+		return "", err
+	}
+
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", e.ClientID)
+	q.Set("redirect_uri", e.RedirectURL)
+	q.Set("scope", strings.Join(e.Scopes, " "))
+	q.Set("state", state)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+	for k, v := range extra {
+		q[k] = v
+	}
+
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+type oauthTokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (e OAuthEndpoints) exchange(ctx context.Context, code, codeVerifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {e.RedirectURL},
+		"client_id":     {e.ClientID},
+		"client_secret": {e.ClientSecret},
+		"code_verifier": {codeVerifier},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var tok oauthTokenResponse
+	if err := doOAuthJSON(req, &tok); err != nil {
+		return "", err
+	}
+
+	if tok.Error != "" {
+		return "", fmt.Errorf("%w: %s %s", ErrOAuthExchange, tok.Error, tok.ErrorDescription)
+	}
+	if tok.AccessToken == "" {
+		return "", fmt.Errorf("%w: no access token returned", ErrOAuthExchange)
+	}
+
+	return tok.AccessToken, nil
+}
+
+func getOAuthJSON(ctx context.Context, endpoint, accessToken string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	return doOAuthJSON(req, out)
+}
+
+func doOAuthJSON(req *http.Request, out any) error {
+	resp, err := oauthHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	// Token endpoints report errors as JSON with a 400, let the caller see them.
+	if resp.StatusCode >= 300 && !(resp.StatusCode == http.StatusBadRequest && json.Valid(body)) {
+		return fmt.Errorf("%s %s: status %d", req.Method, req.URL.Path, resp.StatusCode)
+	}
+
+	return json.Unmarshal(body, out)
+}
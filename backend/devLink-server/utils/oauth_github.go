@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type GitHubProvider struct {
+	OAuthEndpoints
+	APIURL string
+}
+
+// NewGitHubProviderFromEnv configures GitHub from GITHUB_CLIENT_ID and
+// GITHUB_CLIENT_SECRET. GITHUB_AUTH_URL, GITHUB_TOKEN_URL and GITHUB_API_URL
+// override the public endpoints. It returns nil when GitHub is not set up.
+func NewGitHubProviderFromEnv() *GitHubProvider {
+	clientID := os.Getenv("GITHUB_CLIENT_ID")
+	if clientID == "" {
+		return nil
+	}
+
+	return &GitHubProvider{
+		OAuthEndpoints: OAuthEndpoints{
+			ClientID:     clientID,
+			ClientSecret: os.Getenv("GITHUB_CLIENT_SECRET"),
+			RedirectURL:  envOr("GITHUB_REDIRECT_URL", OAuthCallbackURL("github")),
+			AuthURL:      envOr("GITHUB_AUTH_URL", "https://github.com/login/oauth/authorize"),
+			TokenURL:     envOr("GITHUB_TOKEN_URL", "https://github.com/login/oauth/access_token"),
+			Scopes:       []string{"read:user", "user:email"},
+		},
+		APIURL: strings.TrimRight(envOr("GITHUB_API_URL", "https://api.github.com"), "/"),
+	}
+}
+
+func (p *GitHubProvider) Name() string {
+	return "github"
+}
+
+func (p *GitHubProvider) AuthCodeURL(state, codeChallenge string) (string, error) {
+	return p.authCodeURL(state, codeChallenge, nil)
+}
+
+type githubUser struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+func (p *GitHubProvider) Exchange(ctx context.Context, code, codeVerifier string) (OAuthIdentity, error) {
+	accessToken, err := p.exchange(ctx, code, codeVerifier)
+	if err != nil {
+		return OAuthIdentity{}, err
+	}
+
+	var user githubUser
+	if err := getOAuthJSON(ctx, p.APIURL+"/user", accessToken, &user); err != nil {
+		return OAuthIdentity{}, err
+	}
+	if user.ID == 0 {
+		return OAuthIdentity{}, errors.New("github: user id missing")
+	}
+
+	identity := OAuthIdentity{
+		Provider:  p.Name(),
+		Subject:   strconv.FormatInt(user.ID, 10),
+		Email:     user.Email,
+		Name:      user.Name,
+		Username:  user.Login,
+		AvatarURL: user.AvatarURL,
+	}
+
+	// The profile email is whatever the user made public, only the emails
+	// endpoint says whether GitHub has verified it.
+	var emails []githubEmail
+	if err := getOAuthJSON(ctx, p.APIURL+"/user/emails", accessToken, &emails); err != nil {
+		return identity, nil
+	}
+
+	for _, e := range emails {
+		if e.Primary && e.Verified {
+			identity.Email, identity.EmailVerified = e.Email, true
+			return identity, nil
+		}
+	}
+	for _, e := range emails {
+		if e.Verified {
+			identity.Email, identity.EmailVerified = e.Email, true
+			return identity, nil
+		}
+	}
+
+	return identity, nil
+}
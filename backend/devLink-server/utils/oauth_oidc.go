@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// OIDCProvider signs users in with any OpenID Connect provider. Identity is
+// read from the userinfo endpoint with the access token obtained through the
+// PKCE-protected code exchange.
+type OIDCProvider struct {
+	OAuthEndpoints
+	ProviderName string
+	Issuer       string
+	UserInfoURL  string
+
+	mu         sync.Mutex
+	discovered bool
+}
+
+// NewOIDCProviderFromEnv configures a generic provider from OIDC_ISSUER,
+// OIDC_CLIENT_ID and OIDC_CLIENT_SECRET. Endpoints are discovered from the
+// issuer unless OIDC_AUTH_URL, OIDC_TOKEN_URL and OIDC_USERINFO_URL are set.
+// It returns nil when OIDC is not set up.
+func NewOIDCProviderFromEnv() *OIDCProvider {
+	clientID := os.Getenv("OIDC_CLIENT_ID")
+	if clientID == "" {
+		return nil
+	}
+
+	name := envOr("OIDC_NAME", "oidc")
+
+	return &OIDCProvider{
+		OAuthEndpoints: OAuthEndpoints{
+			ClientID:     clientID,
+			ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+			RedirectURL:  envOr("OIDC_REDIRECT_URL", OAuthCallbackURL(name)),
+			AuthURL:      os.Getenv("OIDC_AUTH_URL"),
+			TokenURL:     os.Getenv("OIDC_TOKEN_URL"),
+			Scopes:       strings.Fields(envOr("OIDC_SCOPES", "openid email profile")),
+		},
+		ProviderName: name,
+		Issuer:       strings.TrimRight(os.Getenv("OIDC_ISSUER"), "/"),
+		UserInfoURL:  os.Getenv("OIDC_USERINFO_URL"),
+	}
+}
+
+func (p *OIDCProvider) Name() string {
+	return p.ProviderName
+}
+
+type oidcDiscovery struct {
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+// discover fills in missing endpoints from the issuer metadata. A failed
+// lookup is retried on the next request.
+func (p *OIDCProvider) discover(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovered || (p.AuthURL != "" && p.TokenURL != "" && p.UserInfoURL != "") {
+		return nil
+	}
+	if p.Issuer == "" {
+		return errors.New("oidc: issuer or explicit endpoints required")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	var meta oidcDiscovery
+	if err := doOAuthJSON(req, &meta); err != nil {
+		return err
+	}
+
+	if p.AuthURL == "" {
+		p.AuthURL = meta.AuthorizationEndpoint
+	}
+	if p.TokenURL == "" {
+		p.TokenURL = meta.TokenEndpoint
+	}
+	if p.UserInfoURL == "" {
+		p.UserInfoURL = meta.UserInfoEndpoint
+	}
+	if p.AuthURL == "" || p.TokenURL == "" || p.UserInfoURL == "" {
+		return errors.New("oidc: discovery document is missing endpoints")
+	}
+
+	p.discovered = true
+	return nil
+}
+
+func (p *OIDCProvider) AuthCodeURL(state, codeChallenge string) (string, error) {
+	if err := p.discover(context.Background()); err != nil {
+		return "", err
+	}
+	return p.authCodeURL(state, codeChallenge, nil)
+}
+
+type oidcUserInfo struct {
+	Subject           string `json:"sub"`
+	Email             string `json:"email"`
+	EmailVerified     any    `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	Picture           string `json:"picture"`
+}
+
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier string) (OAuthIdentity, error) {
+	if err := p.discover(ctx); err != nil {
+		return OAuthIdentity{}, err
+	}
+
+	accessToken, err := p.exchange(ctx, code, codeVerifier)
+	if err != nil {
+		return OAuthIdentity{}, err
+	}
+
+	var info oidcUserInfo
+	if err := getOAuthJSON(ctx, p.UserInfoURL, accessToken, &info); err != nil {
+		return OAuthIdentity{}, err
+	}
+	if info.Subject == "" {
+		return OAuthIdentity{}, errors.New("oidc: sub claim missing")
+	}
+
+	// Some providers send email_verified as the string "true".
+	verified := false
+	switch v := info.EmailVerified.(type) {
+	case bool:
+		verified = v
+	case string:
+		verified = v == "true"
+	}
+
+	return OAuthIdentity{
+		Provider:      p.Name(),
+		Subject:       info.Subject,
+		Email:         info.Email,
+		EmailVerified: verified,
+		Name:          info.Name,
+		Username:      info.PreferredUsername,
+		AvatarURL:     info.Picture,
+	}, nil
+}
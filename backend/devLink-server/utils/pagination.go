@@ -0,0 +1,218 @@
+package utils
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Page is one request for a list ordered by a sort field with _id as the
+// tiebreaker. Continuing from a cursor rather than skipping rows keeps pages
+// stable while documents are inserted ahead of them.
+type Page struct {
+	Field string
+	Desc  bool
+	Limit int
+
+	after *pageCursor
+}
+
+// The cursor carries the field it was issued for so it cannot be replayed
+// against a list with a different order.
+type pageCursor struct {
+	Field string        `bson:"f"`
+	Key   bson.RawValue `bson:"k"`
+	ID    bson.ObjectID `bson:"i"`
+}
+
+// NewPage reads the limit and cursor query values. A missing or invalid
+// limit falls back to DefaultPageSize and larger ones are capped at
+// MaxPageSize.
+func NewPage(field string, desc bool, rawLimit, rawCursor string) (Page, error) {
+	page := Page{Field: field, Desc: desc, Limit: DefaultPageSize}
+
+	if limit, err := strconv.Atoi(rawLimit); err == nil && limit > 0 {
+		page.Limit = min(limit, MaxPageSize)
+	}
+
+	if rawCursor == "" {
+		return page, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(rawCursor)
+	if err != nil {
+		return page, ErrInvalidCursor
+	}
+
+	var cur pageCursor
+	if err := bson.Unmarshal(data, &cur); err != nil || cur.Field != field || cur.Key.Type == 0 || cur.ID.IsZero() {
+		return page, ErrInvalidCursor
+	}
+
+	page.after = &cur
+	return page, nil
+}
+
+// Filter narrows filter to the documents after the cursor.
+func (p Page) Filter(filter bson.M) bson.M {
+	if p.after == nil {
+		return filter
+	}
+
+	op := "$gt"
+	if p.Desc {
+		op = "$lt"
+	}
+
+	after := bson.M{"$or": []bson.M{
+		{p.Field: bson.M{op: p.after.Key}},
+		{p.Field: p.after.Key, "_id": bson.M{op: p.after.ID}},
+	}}
+
+	if and, ok := filter["$and"].([]bson.M); ok {
+		filter["$and"] = append(and, after)
+	} else {
+		filter["$and"] = []bson.M{after}
+	}
+	return filter
+}
+
+// After returns the sort key and _id of the last item of the previous page,
+// for lists that are ordered in memory rather than by a query.
+func (p Page) After() (bson.RawValue, bson.ObjectID, bool) {
+	if p.after == nil {
+		return bson.RawValue{}, bson.ObjectID{}, false
+	}
+	return p.after.Key, p.after.ID, true
+}
+
+// Sort orders by the page field and then _id, both in the page direction.
+func (p Page) Sort() bson.D {
+	dir := 1
+	if p.Desc {
+		dir = -1
+	}
+	return bson.D{{Key: p.Field, Value: dir}, {Key: "_id", Value: dir}}
+}
+
+// FindOptions sorts and fetches one document more than the limit, which
+// tells Next whether another page exists.
+func (p Page) FindOptions() *options.FindOptionsBuilder {
+	return options.Find().SetSort(p.Sort()).SetLimit(int64(p.Limit) + 1)
+}
+
+// Next trims the extra document fetched by FindOptions and returns the
+// cursor for the following page, or nil on the last one. key returns the
+// sort field value and _id of an item.
+func Next[T any](p Page, items []T, key func(T) (any, bson.ObjectID)) ([]T, *string) {
+	if len(items) <= p.Limit {
+		return items, nil
+	}
+
+	items = items[:p.Limit]
+	value, id := key(items[len(items)-1])
+
+	data, err := bson.Marshal(bson.M{"f": p.Field, "k": value, "i": id})
+	if err != nil {
+		return items, nil
+	}
+
+	next := base64.RawURLEncoding.EncodeToString(data)
+	return items, &next
+}
+
+// EnsurePaginationIndexes backs the sort orders of the paginated lists.
+func EnsurePaginationIndexes(ctx context.Context, client *mongo.Client) error {
+	if _, err := database.OpenCollection("posts", client).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "published", Value: 1}, {Key: "published_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "published", Value: 1}, {Key: "published_at", Value: -1}, {Key: "_id", Value: -1}}},
+	}); err != nil {
+		return err
+	}
+
+	if _, err := database.OpenCollection("users", client).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}},
+	}); err != nil {
+		return err
+	}
+
+	if _, err := database.OpenCollection("chat_requests", client).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "receiver_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+	}); err != nil {
+		return err
+	}
+
+	_, err := database.OpenCollection("chat_rooms", client).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "participants", Value: 1}, {Key: "last_message_at", Value: -1}, {Key: "_id", Value: -1}},
+	})
+	return err
+}
+
+// BackfillRoomActivity dates rooms that predate last_message_at, or stored
+// it as the zero time, by their newest message or else their creation. The
+// room list pages on that field, and a cursor cannot get past a room
+// without it.
+func BackfillRoomActivity(ctx context.Context, client *mongo.Client) error {
+	roomCollection := database.OpenCollection("chat_rooms", client)
+	messageCollection := database.OpenCollection("messages", client)
+
+	cursor, err := roomCollection.Find(
+		ctx,
+		bson.M{"$or": bson.A{
+			bson.M{"last_message_at": bson.M{"$exists": false}},
+			bson.M{"last_message_at": nil},
+			bson.M{"last_message_at": bson.M{"$lte": time.Time{}}},
+		}},
+		options.Find().SetProjection(bson.M{"created_at": 1}),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var room struct {
+			ID        bson.ObjectID `bson:"_id"`
+			CreatedAt time.Time     `bson:"created_at"`
+		}
+		if err := cursor.Decode(&room); err != nil {
+			continue
+		}
+
+		lastMessageAt := room.CreatedAt
+		var message struct {
+			CreatedAt time.Time `bson:"created_at"`
+		}
+		err := messageCollection.FindOne(
+			ctx,
+			bson.M{"room_id": room.ID},
+			options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetProjection(bson.M{"created_at": 1}),
+		).Decode(&message)
+		if err == nil {
+			lastMessageAt = message.CreatedAt
+		} else if !errors.Is(err, mongo.ErrNoDocuments) {
+			return err
+		}
+
+		if _, err := roomCollection.UpdateOne(ctx, bson.M{"_id": room.ID}, bson.M{
+			"$set": bson.M{"last_message_at": lastMessageAt},
+		}); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
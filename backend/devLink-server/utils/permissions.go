@@ -0,0 +1,77 @@
+package utils
+
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+type Permission string
+
+const (
+	PermUsersList     Permission = "users:list"
+	PermUsersSuspend  Permission = "users:suspend"
+	PermUsersBan      Permission = "users:ban"
+	PermUsersVerify   Permission = "users:verify"
+	PermUsersSetRole  Permission = "users:set_role"
+	PermPostsModerate Permission = "posts:moderate"
+	PermAuditRead     Permission = "audit:read"
+)
+
+var roleRanks = map[string]int{
+	RoleUser:      1,
+	RoleModerator: 2,
+	RoleAdmin:     3,
+}
+
+var rolePermissions = map[string][]Permission{
+	RoleModerator: {
+		PermUsersList,
+		PermUsersSuspend,
+		PermUsersBan,
+		PermPostsModerate,
+		PermAuditRead,
+	},
+	RoleAdmin: {
+		PermUsersList,
+		PermUsersSuspend,
+		PermUsersBan,
+		PermUsersVerify,
+		PermUsersSetRole,
+		PermPostsModerate,
+		PermAuditRead,
+	},
+}
+
+func ValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// RoleRank orders roles by privilege. Unknown roles rank below "user".
+func RoleRank(role string) int {
+	return roleRanks[role]
+}
+
+// RoleAtLeast reports whether role is min or a more privileged role.
+func RoleAtLeast(role, min string) bool {
+	return RoleRank(role) >= RoleRank(min) && RoleRank(role) > 0
+}
+
+func HasPermission(role string, perm Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// PermissionsFor lists what a role may do, for clients that adapt their UI.
+func PermissionsFor(role string) []Permission {
+	perms := rolePermissions[role]
+	if perms == nil {
+		return []Permission{}
+	}
+	return perms
+}
@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	PostDraft     = "draft"
+	PostScheduled = "scheduled"
+	PostPublished = "published"
+	PostArchived  = "archived"
+)
+
+var PostStatuses = []string{PostDraft, PostScheduled, PostPublished, PostArchived}
+
+// MaxScheduleAhead is how far in the future a post can be scheduled.
+const MaxScheduleAhead = 365 * 24 * time.Hour
+
+var (
+	ErrInvalidPostStatus = errors.New("status must be draft, scheduled, published or archived")
+	ErrInvalidPublishAt  = errors.New("scheduled posts need a publish_at in the future, at most a year ahead")
+)
+
+func ValidPostStatus(status string) bool {
+	return slices.Contains(PostStatuses, status)
+}
+
+// SetPostStatus moves post to status, keeping Published in step. publishAt
+// is required for scheduled posts and ignored otherwise. A post going live
+// for the first time gets published_at, which public lists are ordered by,
+// so that a draft started weeks ago shows up as new.
+func SetPostStatus(post *models.Post, status string, publishAt *time.Time, now time.Time) error {
+	switch status {
+	case PostScheduled:
+		if publishAt == nil || !publishAt.After(now) || publishAt.After(now.Add(MaxScheduleAhead)) {
+			return ErrInvalidPublishAt
+		}
+		at := publishAt.UTC()
+		post.PublishAt = &at
+	case PostDraft, PostPublished, PostArchived:
+		post.PublishAt = nil
+	default:
+		return ErrInvalidPostStatus
+	}
+
+	post.Status = status
+	post.Published = status == PostPublished
+	if post.Published && post.PublishedAt == nil {
+		post.PublishedAt = &now
+	}
+	return nil
+}
+
+// PostStatusFields is the status of post as a $set document.
+func PostStatusFields(post models.Post) bson.M {
+	return bson.M{
+		"status":       post.Status,
+		"published":    post.Published,
+		"publish_at":   post.PublishAt,
+		"published_at": post.PublishedAt,
+	}
+}
+
+// EnsurePostStatusIndexes backs the scheduler's due-post query and the
+// author's status filtered content list.
+func EnsurePostStatusIndexes(ctx context.Context, client *mongo.Client) error {
+	_, err := database.OpenCollection("posts", client).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "publish_at", Value: 1}}},
+		{Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "status", Value: 1}, {Key: "updated_at", Value: -1}, {Key: "_id", Value: -1}}},
+	})
+	return err
+}
+
+// BackfillPostStatus gives posts written before statuses existed the status
+// matching their published flag, and published posts without a publication
+// date their creation date. Unpublished posts were the archive.
+func BackfillPostStatus(ctx context.Context, client *mongo.Client) error {
+	postCollection := database.OpenCollection("posts", client)
+
+	if _, err := postCollection.UpdateMany(
+		ctx,
+		bson.M{"published": true, "published_at": bson.M{"$exists": false}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{"published_at": "$created_at"}}}},
+	); err != nil {
+		return err
+	}
+
+	if _, err := postCollection.UpdateMany(
+		ctx,
+		bson.M{"status": bson.M{"$exists": false}, "published": true},
+		bson.M{"$set": bson.M{"status": PostPublished}},
+	); err != nil {
+		return err
+	}
+
+	_, err := postCollection.UpdateMany(
+		ctx,
+		bson.M{"status": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"status": PostArchived, "published": false}},
+	)
+	return err
+}
@@ -0,0 +1,177 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	MaxSkills         = 30
+	MaxPinnedPosts    = 3
+	MaxLocationLength = 100
+	MaxBioLength      = 160
+)
+
+// SkillLevels lists proficiencies from lowest to highest.
+var SkillLevels = []string{"beginner", "intermediate", "advanced", "expert"}
+
+var (
+	skillPattern    = regexp.MustCompile(`^[a-z0-9][a-z0-9+#.\- ]{0,29}$`)
+	gitUserPattern  = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9._-]{0,38})$`)
+	gitLabPathChars = regexp.MustCompile(`^[A-Za-z0-9._/-]+$`)
+)
+
+// NormalizeSkills lowercases skill names, defaults the level to
+// intermediate and rejects duplicates and unknown levels.
+func NormalizeSkills(skills []models.Skill) ([]models.Skill, error) {
+	if len(skills) > MaxSkills {
+		return nil, errors.New("at most 30 skills are allowed")
+	}
+
+	out := []models.Skill{}
+	seen := map[string]bool{}
+	for _, skill := range skills {
+		name := strings.Join(strings.Fields(strings.ToLower(skill.Name)), " ")
+		if !skillPattern.MatchString(name) {
+			return nil, errors.New("invalid skill name: " + skill.Name)
+		}
+		if seen[name] {
+			return nil, errors.New("duplicate skill: " + name)
+		}
+		seen[name] = true
+
+		level := strings.ToLower(strings.TrimSpace(skill.Level))
+		if level == "" {
+			level = "intermediate"
+		}
+		if !slices.Contains(SkillLevels, level) {
+			return nil, errors.New("skill level must be one of " + strings.Join(SkillLevels, ", "))
+		}
+
+		out = append(out, models.Skill{Name: name, Level: level})
+	}
+	return out, nil
+}
+
+// NormalizeProfileLinks accepts either full profile URLs or bare usernames
+// for GitHub and GitLab, and any http(s) URL for the website.
+func NormalizeProfileLinks(links models.ProfileLinks) (models.ProfileLinks, error) {
+	var err error
+
+	if links.GitHub, err = normalizeForgeLink(links.GitHub, "github.com", gitUserPattern); err != nil {
+		return links, errors.New("invalid GitHub link")
+	}
+	if links.GitLab, err = normalizeForgeLink(links.GitLab, "gitlab.com", gitLabPathChars); err != nil {
+		return links, errors.New("invalid GitLab link")
+	}
+
+	links.Website = strings.TrimSpace(links.Website)
+	if links.Website != "" {
+		u, err := url.Parse(links.Website)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(links.Website) > 200 {
+			return links, errors.New("website must be an http or https URL")
+		}
+		links.Website = u.String()
+	}
+	return links, nil
+}
+
+func normalizeForgeLink(value, host string, pathPattern *regexp.Regexp) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+
+	path := strings.TrimPrefix(value, "@")
+	if strings.Contains(value, "://") || strings.HasPrefix(value, host) {
+		u, err := url.Parse(value)
+		if err == nil && u.Scheme == "" {
+			u, err = url.Parse("https://" + value)
+		}
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || strings.TrimPrefix(u.Host, "www.") != host {
+			return "", errors.New("wrong host")
+		}
+		path = u.Path
+	}
+
+	path = strings.Trim(path, "/")
+	if path == "" || len(path) > 100 || !pathPattern.MatchString(path) {
+		return "", errors.New("invalid path")
+	}
+	return "https://" + host + "/" + path, nil
+}
+
+// ValidTimezone reports whether tz is an IANA zone name such as
+// Europe/Berlin.
+func ValidTimezone(tz string) bool {
+	if tz == "" || tz == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(tz)
+	return err == nil
+}
+
+var (
+	// ErrInvalidPinnedPost is returned when a pinned id is not one of the
+	// user's published posts.
+	ErrInvalidPinnedPost  = errors.New("pinned posts must be your own published posts")
+	ErrTooManyPinnedPosts = errors.New("at most 3 posts can be pinned")
+)
+
+// ParsePinnedPosts checks that every id names a published post by the user
+// and keeps the given order.
+func ParsePinnedPosts(ctx context.Context, client *mongo.Client, userID bson.ObjectID, ids []string) ([]bson.ObjectID, error) {
+	if len(ids) > MaxPinnedPosts {
+		return nil, ErrTooManyPinnedPosts
+	}
+
+	pinned := []bson.ObjectID{}
+	for _, id := range ids {
+		oid, err := bson.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, ErrInvalidPinnedPost
+		}
+		if !slices.Contains(pinned, oid) {
+			pinned = append(pinned, oid)
+		}
+	}
+
+	if len(pinned) == 0 {
+		return pinned, nil
+	}
+
+	count, err := database.OpenCollection("posts", client).CountDocuments(ctx, bson.M{
+		"_id":       bson.M{"$in": pinned},
+		"author_id": userID,
+		"published": true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if count != int64(len(pinned)) {
+		return nil, ErrInvalidPinnedPost
+	}
+	return pinned, nil
+}
+
+// EnsureProfileIndexes supports the skill and availability filters of the
+// user search.
+func EnsureProfileIndexes(ctx context.Context, client *mongo.Client) error {
+	_, err := database.OpenCollection("users", client).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "skills.name", Value: 1}}},
+		{Keys: bson.D{{Key: "open_to_collaborate", Value: 1}}},
+		{Keys: bson.D{{Key: "open_to_hire", Value: 1}}},
+	})
+	return err
+}
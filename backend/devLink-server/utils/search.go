@@ -0,0 +1,340 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ayushmehta03/devLink-backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	MaxSearchQueryLength = 200
+
+	maxSearchTerms = 10
+	snippetLength  = 200
+	snippetLead    = 60
+)
+
+// Relative weight of a match in each post field.
+const (
+	searchTitleWeight   = 10
+	searchTagsWeight    = 5
+	searchContentWeight = 1
+)
+
+// SearchQuery is a post search. Text supports plain words, "quoted phrases"
+// and prefix* terms; the remaining fields narrow the results. Page must be
+// ordered by "score".
+type SearchQuery struct {
+	Text           string
+	AuthorID       *bson.ObjectID
+	Tags           []string
+	From           *time.Time
+	Before         *time.Time
+	ExcludeAuthors []bson.ObjectID
+	Page           Page
+}
+
+// HasFilters reports whether the query narrows results by anything besides
+// its text.
+func (q SearchQuery) HasFilters() bool {
+	return q.AuthorID != nil || len(q.Tags) > 0 || q.From != nil || q.Before != nil
+}
+
+// SearchHit is a matching post with its relevance and HTML-escaped title and
+// snippet in which matches are wrapped in <mark>.
+type SearchHit struct {
+	Post    models.Post
+	Score   float64
+	Title   string
+	Snippet string
+}
+
+type SearchResult struct {
+	Hits       []SearchHit
+	NextCursor *string
+}
+
+// Searcher finds published posts by relevance. Index and Remove are called
+// on every post write for implementations that keep their own index.
+type Searcher interface {
+	Search(ctx context.Context, q SearchQuery) (SearchResult, error)
+	Index(ctx context.Context, post models.Post) error
+	Remove(ctx context.Context, id bson.ObjectID) error
+}
+
+// NewSearcherFromEnv picks the implementation named by SEARCH_BACKEND: the
+// MongoDB text index by default, or "memory" for an index held in process
+// that needs no text index and suits local development.
+func NewSearcherFromEnv(client *mongo.Client) (Searcher, error) {
+	switch backend := strings.ToLower(os.Getenv("SEARCH_BACKEND")); backend {
+	case "", "mongo":
+		return NewMongoSearcher(client), nil
+
+	case "memory":
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		searcher := NewMemorySearcher(client)
+		if err := searcher.Load(ctx); err != nil {
+			return nil, err
+		}
+		return searcher, nil
+
+	default:
+		return nil, fmt.Errorf("unknown SEARCH_BACKEND %q", backend)
+	}
+}
+
+// searchTerms is a parsed query. Documents match any term or prefix, and
+// must contain every phrase.
+type searchTerms struct {
+	Terms    []string
+	Prefixes []string
+	Phrases  [][]string
+}
+
+func (t searchTerms) empty() bool {
+	return len(t.Terms) == 0 && len(t.Prefixes) == 0 && len(t.Phrases) == 0
+}
+
+// Words too common to be worth matching on their own, as the MongoDB text
+// index drops them too.
+var searchStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "in": true, "is": true,
+	"it": true, "of": true, "on": true, "or": true, "that": true, "the": true,
+	"this": true, "to": true, "was": true, "with": true,
+}
+
+func parseSearchQuery(raw string) searchTerms {
+	var t searchTerms
+	count := 0
+
+	add := func(list *[]string, word string) {
+		if count < maxSearchTerms && !slices.Contains(*list, word) {
+			*list = append(*list, word)
+			count++
+		}
+	}
+
+	// Odd parts sit between quotes; an unterminated quote runs to the end.
+	for i, part := range strings.Split(raw, `"`) {
+		if i%2 == 1 {
+			words := searchTokens(part)
+			if len(words) == 1 {
+				add(&t.Terms, words[0])
+			} else if len(words) > 1 && count < maxSearchTerms {
+				t.Phrases = append(t.Phrases, words)
+				count++
+			}
+			continue
+		}
+
+		for _, field := range strings.Fields(part) {
+			words := searchTokens(field)
+			for j, word := range words {
+				switch {
+				case j == len(words)-1 && strings.HasSuffix(field, "*"):
+					if len(word) >= 2 {
+						add(&t.Prefixes, word)
+					}
+				case !searchStopWords[word]:
+					add(&t.Terms, word)
+				}
+			}
+		}
+	}
+	return t
+}
+
+type tokenSpan struct {
+	Start, End int
+}
+
+// tokenSpans finds the words of text as byte ranges.
+func tokenSpans(text string) []tokenSpan {
+	spans := []tokenSpan{}
+	start := -1
+	for i, r := range text {
+		word := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if word && start < 0 {
+			start = i
+		}
+		if !word && start >= 0 {
+			spans = append(spans, tokenSpan{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, tokenSpan{start, len(text)})
+	}
+	return spans
+}
+
+// searchTokens splits text into lowercase words.
+func searchTokens(text string) []string {
+	spans := tokenSpans(text)
+	words := make([]string, 0, len(spans))
+	for _, s := range spans {
+		words = append(words, strings.ToLower(text[s.Start:s.End]))
+	}
+	return words
+}
+
+// matchesWord reports whether a document word should be highlighted for
+// the query. Longer terms also match words they start, which roughly
+// follows the stemming of the MongoDB text index.
+func (t searchTerms) matchesWord(word string) bool {
+	for _, term := range t.Terms {
+		if word == term || (len(term) >= 4 && strings.HasPrefix(word, term)) {
+			return true
+		}
+	}
+	for _, prefix := range t.Prefixes {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// markedWords flags the words of text that match the query.
+func (t searchTerms) markedWords(words []string) []bool {
+	marked := make([]bool, len(words))
+	for i, word := range words {
+		marked[i] = t.matchesWord(word)
+	}
+	for _, phrase := range t.Phrases {
+		for i := 0; i+len(phrase) <= len(words); i++ {
+			if slices.Equal(words[i:i+len(phrase)], phrase) {
+				for j := range phrase {
+					marked[i+j] = true
+				}
+			}
+		}
+	}
+	return marked
+}
+
+// highlight escapes text[from:to] and wraps the marked words in <mark>.
+func highlight(text string, spans []tokenSpan, marked []bool, from, to int) string {
+	var b strings.Builder
+	pos := from
+	for i, s := range spans {
+		if !marked[i] || s.Start < from || s.End > to {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:s.Start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[s.Start:s.End]))
+		b.WriteString("</mark>")
+		pos = s.End
+	}
+	b.WriteString(html.EscapeString(text[pos:to]))
+	return b.String()
+}
+
+var (
+	markdownFence  = regexp.MustCompile("(?m)^\\s*(```|~~~).*$")
+	markdownLink   = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	markdownPrefix = regexp.MustCompile(`(?m)^\s*(#{1,6}|>|[-*+]|\d+\.)\s+`)
+	markdownMarks  = regexp.MustCompile("[*_`~]+")
+)
+
+// plainText reduces Markdown to its words for snippets.
+func plainText(markdown string) string {
+	text := markdownFence.ReplaceAllString(markdown, "")
+	text = markdownLink.ReplaceAllString(text, "$1")
+	text = markdownPrefix.ReplaceAllString(text, "")
+	text = markdownMarks.ReplaceAllString(text, "")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+type scoredPost struct {
+	models.Post `bson:",inline"`
+	Score       float64 `bson:"score"`
+}
+
+// searchResult cuts one page from ranked posts and highlights it.
+func searchResult(page Page, ranked []scoredPost, terms searchTerms) SearchResult {
+	ranked, next := Next(page, ranked, scoredPostKey)
+
+	result := SearchResult{Hits: []SearchHit{}, NextCursor: next}
+	for _, p := range ranked {
+		result.Hits = append(result.Hits, newSearchHit(p.Post, p.Score, terms))
+	}
+	return result
+}
+
+func scoredPostKey(p scoredPost) (any, bson.ObjectID) {
+	return p.Score, p.ID
+}
+
+// newSearchHit highlights the title and cuts a snippet of the content
+// around its first match.
+func newSearchHit(post models.Post, score float64, t searchTerms) SearchHit {
+	hit := SearchHit{Post: post, Score: score}
+
+	titleSpans := tokenSpans(post.Title)
+	titleWords := make([]string, len(titleSpans))
+	for i, s := range titleSpans {
+		titleWords[i] = strings.ToLower(post.Title[s.Start:s.End])
+	}
+	hit.Title = highlight(post.Title, titleSpans, t.markedWords(titleWords), 0, len(post.Title))
+
+	text := plainText(post.Content)
+	spans := tokenSpans(text)
+	words := make([]string, len(spans))
+	for i, s := range spans {
+		words[i] = strings.ToLower(text[s.Start:s.End])
+	}
+	marked := t.markedWords(words)
+
+	first := slices.Index(marked, true)
+	if first < 0 {
+		first = 0
+	}
+
+	from, to := 0, len(text)
+	if len(spans) > 0 {
+		// Start a few words before the match and stop at the last whole word
+		// that fits.
+		lead := first
+		for lead > 0 && spans[first].Start-spans[lead-1].Start <= snippetLead {
+			lead--
+		}
+		if lead > 0 {
+			from = spans[lead].Start
+		}
+
+		to = spans[lead].End
+		for _, s := range spans[lead:] {
+			if s.End-from > snippetLength {
+				break
+			}
+			to = s.End
+		}
+		if to == spans[len(spans)-1].End {
+			to = len(text)
+		}
+	}
+
+	hit.Snippet = highlight(text, spans, marked, from, to)
+	if from > 0 {
+		hit.Snippet = "…" + hit.Snippet
+	}
+	if to < len(text) {
+		hit.Snippet += "…"
+	}
+	return hit
+}
@@ -0,0 +1,301 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Fields of an indexed post, in the order of memoryFieldWeights.
+const (
+	memoryTitle = iota
+	memoryTags
+	memoryContent
+	memoryFieldCount
+)
+
+var memoryFieldWeights = [memoryFieldCount]float64{searchTitleWeight, searchTagsWeight, searchContentWeight}
+
+// Term frequency saturation, as in BM25: repeating a word keeps adding to
+// the score, but less each time.
+const memorySaturation = 1.2
+
+type memoryDoc struct {
+	AuthorID    bson.ObjectID
+	Tags        []string
+	PublishedAt time.Time
+	Words       [memoryFieldCount][]string
+}
+
+// MemorySearcher keeps an inverted index of published posts in process.
+// It is filled from MongoDB by Load and kept current through Index and
+// Remove; matching posts are read back from MongoDB, so posts unpublished
+// or deleted elsewhere drop out of the results.
+type MemorySearcher struct {
+	client *mongo.Client
+
+	mu       sync.RWMutex
+	docs     map[bson.ObjectID]*memoryDoc
+	postings map[string]map[bson.ObjectID]*[memoryFieldCount]int
+}
+
+func NewMemorySearcher(client *mongo.Client) *MemorySearcher {
+	return &MemorySearcher{
+		client:   client,
+		docs:     map[bson.ObjectID]*memoryDoc{},
+		postings: map[string]map[bson.ObjectID]*[memoryFieldCount]int{},
+	}
+}
+
+// Load indexes every published post.
+func (s *MemorySearcher) Load(ctx context.Context) error {
+	cursor, err := database.OpenCollection("posts", s.client).Find(ctx, bson.M{"published": true})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var post models.Post
+		if err := cursor.Decode(&post); err != nil {
+			return err
+		}
+		s.Index(ctx, post)
+	}
+	return cursor.Err()
+}
+
+func (s *MemorySearcher) Index(ctx context.Context, post models.Post) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(post.ID)
+	if !post.Published {
+		return nil
+	}
+
+	doc := &memoryDoc{AuthorID: post.AuthorID, Tags: post.Tags, PublishedAt: post.CreatedAt}
+	if post.PublishedAt != nil {
+		doc.PublishedAt = *post.PublishedAt
+	}
+	doc.Words[memoryTitle] = searchTokens(post.Title)
+	doc.Words[memoryTags] = searchTokens(strings.Join(post.Tags, " "))
+	doc.Words[memoryContent] = searchTokens(plainText(post.Content))
+	s.docs[post.ID] = doc
+
+	for field, words := range doc.Words {
+		for _, word := range words {
+			docs := s.postings[word]
+			if docs == nil {
+				docs = map[bson.ObjectID]*[memoryFieldCount]int{}
+				s.postings[word] = docs
+			}
+			if docs[post.ID] == nil {
+				docs[post.ID] = &[memoryFieldCount]int{}
+			}
+			docs[post.ID][field]++
+		}
+	}
+	return nil
+}
+
+func (s *MemorySearcher) Remove(ctx context.Context, id bson.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(id)
+	return nil
+}
+
+func (s *MemorySearcher) remove(id bson.ObjectID) {
+	doc, ok := s.docs[id]
+	if !ok {
+		return
+	}
+	for _, words := range doc.Words {
+		for _, word := range words {
+			delete(s.postings[word], id)
+			if len(s.postings[word]) == 0 {
+				delete(s.postings, word)
+			}
+		}
+	}
+	delete(s.docs, id)
+}
+
+func (s *MemorySearcher) Search(ctx context.Context, q SearchQuery) (SearchResult, error) {
+	terms := parseSearchQuery(q.Text)
+	ranked, next := Next(q.Page, s.rank(q, terms), scoredPostKey)
+
+	// Read the page back from MongoDB for current counts and to skip posts
+	// that were unpublished or deleted without passing through Remove.
+	ids := []bson.ObjectID{}
+	for _, p := range ranked {
+		ids = append(ids, p.ID)
+	}
+
+	posts := map[bson.ObjectID]models.Post{}
+	if len(ids) > 0 {
+		filter := searchFilter(SearchQuery{ExcludeAuthors: q.ExcludeAuthors})
+		filter["_id"] = bson.M{"$in": ids}
+
+		cursor, err := database.OpenCollection("posts", s.client).Find(ctx, filter)
+		if err != nil {
+			return SearchResult{}, err
+		}
+
+		current := []models.Post{}
+		if err := cursor.All(ctx, &current); err != nil {
+			return SearchResult{}, err
+		}
+		for _, post := range current {
+			posts[post.ID] = post
+		}
+	}
+
+	result := SearchResult{Hits: []SearchHit{}, NextCursor: next}
+	for _, p := range ranked {
+		if post, ok := posts[p.ID]; ok {
+			result.Hits = append(result.Hits, newSearchHit(post, p.Score, terms))
+		}
+	}
+	return result, nil
+}
+
+// rank scores the matching documents and returns those after the page
+// cursor, best first, one more than the page limit.
+func (s *MemorySearcher) rank(q SearchQuery, terms searchTerms) []scoredPost {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	total := float64(len(s.docs))
+	idf := func(word string) float64 {
+		return math.Log(1 + total/float64(len(s.postings[word])+1))
+	}
+
+	scores := map[bson.ObjectID]float64{}
+	addWord := func(word string, weight float64) {
+		for id, counts := range s.postings[word] {
+			tf := 0.0
+			for field, n := range counts {
+				tf += memoryFieldWeights[field] * float64(n)
+			}
+			scores[id] += weight * idf(word) * tf * (memorySaturation + 1) / (tf + memorySaturation)
+		}
+	}
+
+	for _, term := range terms.Terms {
+		addWord(term, 1)
+	}
+	for _, prefix := range terms.Prefixes {
+		for word := range s.postings {
+			if strings.HasPrefix(word, prefix) {
+				addWord(word, 0.5)
+			}
+		}
+	}
+
+	var candidates map[bson.ObjectID]bool
+	if len(terms.Phrases) > 0 {
+		// Like $text, a phrase is required and the other words only rank.
+		candidates = map[bson.ObjectID]bool{}
+		for id := range s.postings[terms.Phrases[0][0]] {
+			candidates[id] = true
+		}
+		for id := range candidates {
+			for _, phrase := range terms.Phrases {
+				if !s.docs[id].contains(phrase) {
+					delete(candidates, id)
+					break
+				}
+			}
+		}
+		for id := range candidates {
+			for _, phrase := range terms.Phrases {
+				for _, word := range phrase {
+					scores[id] += idf(word)
+				}
+			}
+		}
+	} else if terms.empty() {
+		// Only filters: every post is a candidate.
+		for id := range s.docs {
+			scores[id] = 0
+		}
+	}
+
+	ranked := []scoredPost{}
+	for id, score := range scores {
+		if candidates != nil && !candidates[id] {
+			continue
+		}
+		if s.docs[id].matches(q) {
+			ranked = append(ranked, scoredPost{Post: models.Post{ID: id}, Score: score})
+		}
+	}
+
+	slices.SortFunc(ranked, func(a, b scoredPost) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return bytes.Compare(b.ID[:], a.ID[:])
+	})
+
+	if key, id, ok := q.Page.After(); ok {
+		after, _ := key.DoubleOK()
+		ranked = slices.DeleteFunc(ranked, func(p scoredPost) bool {
+			return p.Score > after || (p.Score == after && bytes.Compare(p.ID[:], id[:]) >= 0)
+		})
+	}
+
+	if len(ranked) > q.Page.Limit+1 {
+		ranked = ranked[:q.Page.Limit+1]
+	}
+	return ranked
+}
+
+// contains reports whether the words of phrase appear in sequence in one
+// of the fields.
+func (d *memoryDoc) contains(phrase []string) bool {
+	for _, words := range d.Words {
+		for i := 0; i+len(phrase) <= len(words); i++ {
+			if slices.Equal(words[i:i+len(phrase)], phrase) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (d *memoryDoc) matches(q SearchQuery) bool {
+	if q.AuthorID != nil && d.AuthorID != *q.AuthorID {
+		return false
+	}
+	if slices.Contains(q.ExcludeAuthors, d.AuthorID) {
+		return false
+	}
+	for _, tag := range q.Tags {
+		if !slices.Contains(d.Tags, tag) {
+			return false
+		}
+	}
+	if q.From != nil && d.PublishedAt.Before(*q.From) {
+		return false
+	}
+	if q.Before != nil && !d.PublishedAt.Before(*q.Before) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// MongoSearcher searches the weighted text index on posts. The index does
+// its own stemming and keeps itself up to date, so Index and Remove do
+// nothing.
+type MongoSearcher struct {
+	client *mongo.Client
+}
+
+func NewMongoSearcher(client *mongo.Client) *MongoSearcher {
+	return &MongoSearcher{client: client}
+}
+
+// EnsureSearchIndexes creates the text index used by MongoSearcher. A
+// collection has at most one text index, so it covers all searched fields.
+func EnsureSearchIndexes(ctx context.Context, client *mongo.Client) error {
+	_, err := database.OpenCollection("posts", client).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "title", Value: "text"},
+			{Key: "tags", Value: "text"},
+			{Key: "content", Value: "text"},
+		},
+		Options: options.Index().
+			SetName("post_search").
+			SetWeights(bson.D{
+				{Key: "title", Value: searchTitleWeight},
+				{Key: "tags", Value: searchTagsWeight},
+				{Key: "content", Value: searchContentWeight},
+			}),
+	})
+	return err
+}
+
+func (s *MongoSearcher) Index(ctx context.Context, post models.Post) error {
+	return nil
+}
+
+func (s *MongoSearcher) Remove(ctx context.Context, id bson.ObjectID) error {
+	return nil
+}
+
+// searchFilter restricts a search to published posts matching the filters.
+func searchFilter(q SearchQuery) bson.M {
+	filter := bson.M{"published": true}
+
+	author := bson.M{}
+	if q.AuthorID != nil {
+		author["$eq"] = *q.AuthorID
+	}
+	if len(q.ExcludeAuthors) > 0 {
+		author["$nin"] = q.ExcludeAuthors
+	}
+	if len(author) > 0 {
+		filter["author_id"] = author
+	}
+
+	if len(q.Tags) > 0 {
+		filter["tags"] = bson.M{"$all": q.Tags}
+	}
+
+	published := bson.M{}
+	if q.From != nil {
+		published["$gte"] = *q.From
+	}
+	if q.Before != nil {
+		published["$lt"] = *q.Before
+	}
+	if len(published) > 0 {
+		filter["published_at"] = published
+	}
+	return filter
+}
+
+// Search ranks by text score. $text cannot be combined with $or, so when
+// the query has whole words, prefix terms only add to the score of posts
+// that already match; a query of only prefixes matches them by regex.
+func (s *MongoSearcher) Search(ctx context.Context, q SearchQuery) (SearchResult, error) {
+	terms := parseSearchQuery(q.Text)
+	match := searchFilter(q)
+
+	words := append([]string{}, terms.Terms...)
+	for _, phrase := range terms.Phrases {
+		words = append(words, `"`+strings.Join(phrase, " ")+`"`)
+	}
+
+	score := bson.A{}
+	if len(words) > 0 {
+		match["$text"] = bson.M{"$search": strings.Join(words, " ")}
+		score = append(score, bson.M{"$meta": "textScore"})
+	}
+
+	prefixMatches := []bson.M{}
+	for _, prefix := range terms.Prefixes {
+		pattern := `\b` + regexp.QuoteMeta(prefix)
+		regex := bson.M{"$regex": pattern, "$options": "i"}
+		prefixMatches = append(prefixMatches,
+			bson.M{"title": regex},
+			bson.M{"tags": regex},
+			bson.M{"content": regex},
+		)
+
+		matches := func(input any) bson.M {
+			return bson.M{"$regexMatch": bson.M{"input": input, "regex": pattern, "options": "i"}}
+		}
+		score = append(score,
+			bson.M{"$cond": bson.A{matches("$title"), searchTitleWeight, 0}},
+			bson.M{"$cond": bson.A{
+				bson.M{"$anyElementTrue": bson.A{bson.M{"$map": bson.M{
+					"input": bson.M{"$ifNull": bson.A{"$tags", bson.A{}}},
+					"in":    matches("$$this"),
+				}}}},
+				searchTagsWeight,
+				0,
+			}},
+			bson.M{"$cond": bson.A{matches("$content"), searchContentWeight, 0}},
+		)
+	}
+	if len(words) == 0 && len(prefixMatches) > 0 {
+		match["$or"] = prefixMatches
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$addFields", Value: bson.M{"score": bson.M{"$add": append(score, 0)}}}},
+	}
+	if after := q.Page.Filter(bson.M{}); len(after) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: after}})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: q.Page.Sort()}},
+		bson.D{{Key: "$limit", Value: q.Page.Limit + 1}},
+	)
+
+	cursor, err := database.OpenCollection("posts", s.client).Aggregate(ctx, pipeline)
+	if err != nil {
+		return SearchResult{}, err
+	}
+
+	found := []scoredPost{}
+	if err := cursor.All(ctx, &found); err != nil {
+		return SearchResult{}, err
+	}
+	return searchResult(q.Page, found, terms), nil
+}
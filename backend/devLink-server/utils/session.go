@@ -0,0 +1,261 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"github.com/ayushmehta03/devLink-backend/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+// Only the most recent rotated hashes are kept for reuse detection.
+const maxRotatedHashes = 50
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrSessionRevoked      = errors.New("session revoked or expired")
+	ErrRefreshTokenReuse   = errors.New("refresh token reuse detected")
+)
+
+func newRefreshToken(sessionID bson.ObjectID) (string, error) {
+	secret, err := GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	return sessionID.Hex() + "." + secret, nil
+}
+
+func parseRefreshToken(token string) (bson.ObjectID, error) {
+	sid, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return bson.ObjectID{}, ErrInvalidRefreshToken
+	}
+	id, err := bson.ObjectIDFromHex(sid)
+	if err != nil {
+		return bson.ObjectID{}, ErrInvalidRefreshToken
+	}
+	return id, nil
+}
+
+// EnsureSessionIndexes backs the per-user session lists and lets Mongo drop
+// sessions once their refresh token has expired.
+func EnsureSessionIndexes(ctx context.Context, client *mongo.Client) error {
+	_, err := database.OpenCollection("sessions", client).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	return err
+}
+
+// CreateSession starts a new refresh token family for the user and returns
+// the session together with its first refresh token.
+func CreateSession(ctx context.Context, client *mongo.Client, userID bson.ObjectID, userAgent, ip string) (models.Session, string, error) {
+	now := time.Now()
+
+	session := models.Session{
+		ID:         bson.NewObjectID(),
+		UserID:     userID,
+		UserAgent:  userAgent,
+		IP:         ip,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(RefreshTokenTTL),
+	}
+
+	refreshToken, err := newRefreshToken(session.ID)
+	if err != nil {
+		return models.Session{}, "", err
+	}
+	session.RefreshHash = HashToken(refreshToken)
+
+	if _, err := database.OpenCollection("sessions", client).InsertOne(ctx, session); err != nil {
+		return models.Session{}, "", err
+	}
+
+	return session, refreshToken, nil
+}
+
+// RotateSession exchanges a refresh token for a new one. Presenting a token
+// that was already rotated away revokes the whole session.
+func RotateSession(ctx context.Context, client *mongo.Client, refreshToken, ip string) (models.Session, string, error) {
+	sessionID, err := parseRefreshToken(refreshToken)
+	if err != nil {
+		return models.Session{}, "", err
+	}
+
+	sessionCol := database.OpenCollection("sessions", client)
+
+	newToken, err := newRefreshToken(sessionID)
+	if err != nil {
+		return models.Session{}, "", err
+	}
+
+	oldHash := HashToken(refreshToken)
+	now := time.Now()
+
+	var session models.Session
+	err = sessionCol.FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"_id":          sessionID,
+			"refresh_hash": oldHash,
+			"revoked_at":   bson.M{"$exists": false},
+			"expires_at":   bson.M{"$gt": now},
+		},
+		bson.M{
+			"$set": bson.M{
+				"refresh_hash": HashToken(newToken),
+				"last_used_at": now,
+				"ip":           ip,
+				"expires_at":   now.Add(RefreshTokenTTL),
+			},
+			"$push": bson.M{
+				"rotated_hashes": bson.M{
+					"$each":  []string{oldHash},
+					"$slice": -maxRotatedHashes,
+				},
+			},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&session)
+
+	if err == nil {
+		return session, newToken, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return models.Session{}, "", err
+	}
+
+	if err := sessionCol.FindOne(ctx, bson.M{"_id": sessionID}).Decode(&session); err != nil {
+		return models.Session{}, "", ErrInvalidRefreshToken
+	}
+
+	for _, h := range session.RotatedHashes {
+		if h == oldHash {
+			RevokeSession(ctx, client, session.ID)
+			return models.Session{}, "", ErrRefreshTokenReuse
+		}
+	}
+
+	if session.RevokedAt != nil || now.After(session.ExpiresAt) {
+		return models.Session{}, "", ErrSessionRevoked
+	}
+
+	return models.Session{}, "", ErrInvalidRefreshToken
+}
+
+func RevokeSession(ctx context.Context, client *mongo.Client, sessionID bson.ObjectID) error {
+	_, err := database.OpenCollection("sessions", client).UpdateOne(
+		ctx,
+		bson.M{"_id": sessionID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	return err
+}
+
+// RevokeSessionByRefreshToken revokes the session a refresh token belongs to,
+// provided the token is the session's current one.
+func RevokeSessionByRefreshToken(ctx context.Context, client *mongo.Client, refreshToken string) error {
+	sessionID, err := parseRefreshToken(refreshToken)
+	if err != nil {
+		return err
+	}
+
+	_, err = database.OpenCollection("sessions", client).UpdateOne(
+		ctx,
+		bson.M{
+			"_id":          sessionID,
+			"refresh_hash": HashToken(refreshToken),
+			"revoked_at":   bson.M{"$exists": false},
+		},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	return err
+}
+
+func IsSessionActive(ctx context.Context, client *mongo.Client, sessionID string) bool {
+	id, err := bson.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return false
+	}
+
+	count, err := database.OpenCollection("sessions", client).CountDocuments(ctx, bson.M{
+		"_id":        id,
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": time.Now()},
+	})
+
+	return err == nil && count > 0
+}
+
+func ListActiveSessions(ctx context.Context, client *mongo.Client, userID bson.ObjectID) ([]models.Session, error) {
+	cursor, err := database.OpenCollection("sessions", client).Find(
+		ctx,
+		bson.M{
+			"user_id":    userID,
+			"revoked_at": bson.M{"$exists": false},
+			"expires_at": bson.M{"$gt": time.Now()},
+		},
+		options.Find().SetSort(bson.D{{Key: "last_used_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	sessions := []models.Session{}
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// RevokeUserSession revokes one of the user's sessions and reports whether
+// a matching active session existed.
+func RevokeUserSession(ctx context.Context, client *mongo.Client, userID, sessionID bson.ObjectID) (bool, error) {
+	res, err := database.OpenCollection("sessions", client).UpdateOne(
+		ctx,
+		bson.M{
+			"_id":        sessionID,
+			"user_id":    userID,
+			"revoked_at": bson.M{"$exists": false},
+		},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// RevokeUserSessions revokes every active session of the user except the
+// ones listed in keep.
+func RevokeUserSessions(ctx context.Context, client *mongo.Client, userID bson.ObjectID, keep ...bson.ObjectID) (int64, error) {
+	filter := bson.M{
+		"user_id":    userID,
+		"revoked_at": bson.M{"$exists": false},
+	}
+	if len(keep) > 0 {
+		filter["_id"] = bson.M{"$nin": keep}
+	}
+
+	res, err := database.OpenCollection("sessions", client).UpdateMany(
+		ctx,
+		filter,
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
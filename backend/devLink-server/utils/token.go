@@ -1,51 +1,45 @@
 package utils
 
 import (
-	"os"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+const AccessTokenTTL = 15 * time.Minute
+
 
 type JWTClaims struct{
 	UserID string `json:"user_id"`
 	Email string `json:"email"`
 	Role string `json:"role"`
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
 
-func GenerateToken(userId,email,role string)(string,error){
-
-	secret:=os.Getenv("JWT_SECRET");
-
+func GenerateToken(userId,email,role,sessionId string)(string,error){
 
 	claims:=JWTClaims{
 		UserID: userId,
 		Email: email,
 		Role: role,
+		SessionID: sessionId,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24*time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			IssuedAt: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token:=jwt.NewWithClaims(jwt.SigningMethodHS256,claims)
-
-	return token.SignedString([]byte(secret))
+	return Keys().Sign(claims)
 }
 
 func VerifyToken(tokenStr string) (*JWTClaims, error) {
-	secret := os.Getenv("JWT_SECRET")
-
-	token, err := jwt.ParseWithClaims(
-		tokenStr,
-		&JWTClaims{},
-		func(token *jwt.Token) (interface{}, error) {
-			return []byte(secret), nil
-		},
-	)
+	token, err := Keys().Parse(tokenStr, &JWTClaims{})
 
 	if err != nil {
 		return nil, err
@@ -59,3 +53,55 @@ func VerifyToken(tokenStr string) (*JWTClaims, error) {
 	return claims, nil
 }
 
+type PurposeClaims struct {
+	UserID    string `json:"user_id"`
+	Type      string `json:"type"`
+	NonceHash string `json:"nh,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// GeneratePurposeToken mints a short-lived token that is only accepted by
+// VerifyPurposeToken for the same purpose, never as an access token.
+func GeneratePurposeToken(userId, purpose string, ttl time.Duration) (string, error) {
+	claims := PurposeClaims{
+		UserID: userId,
+		Type:   purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	return signPurposeClaims(claims)
+}
+
+func signPurposeClaims(claims PurposeClaims) (string, error) {
+	return Keys().Sign(claims)
+}
+
+func VerifyPurposeToken(tokenStr, purpose string) (*PurposeClaims, error) {
+	token, err := Keys().Parse(tokenStr, &PurposeClaims{})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*PurposeClaims)
+	if !ok || !token.Valid || claims.Type != purpose {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+
+	return claims, nil
+}
+
+func GenerateRandomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
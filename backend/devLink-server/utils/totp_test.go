@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// The SHA-1 secret of RFC 6238 appendix B, "12345678901234567890".
+const rfcTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeMatchesRFC6238(t *testing.T) {
+	// The RFC lists 8 digit codes; six digit codes are their last six.
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := TOTPCode(rfcTOTPSecret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != tt.code {
+			t.Errorf("TOTPCode at %d = %s, want %s", tt.unix, code, tt.code)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	issued := time.Unix(1234567890, 0)
+	step := TOTPStep(issued)
+
+	tests := []struct {
+		name string
+		code string
+		now  time.Time
+		ok   bool
+	}{
+		{"same step", "005924", issued, true},
+		{"spaces", " 005 924 ", issued, true},
+		{"one step late", "005924", issued.Add(TOTPPeriod * time.Second), true},
+		{"one step early", "005924", issued.Add(-TOTPPeriod * time.Second), true},
+		{"two steps late", "005924", issued.Add(2 * TOTPPeriod * time.Second), false},
+		{"wrong code", "005925", issued, false},
+		{"too short", "05924", issued, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ValidateTOTP(rfcTOTPSecret, tt.code, tt.now)
+			if ok != tt.ok {
+				t.Fatalf("ValidateTOTP ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && got != step {
+				t.Errorf("ValidateTOTP step = %d, want %d", got, step)
+			}
+		})
+	}
+}
+
+func TestValidateTOTPBadSecret(t *testing.T) {
+	if _, ok := ValidateTOTP("not base32!", "123456", time.Now()); ok {
+		t.Error("ValidateTOTP accepted a code for an invalid secret")
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	for _, in := range []string{"abcd-efgh", " ABCD-EFGH ", "abcdefgh", "abcd efgh"} {
+		if got := NormalizeRecoveryCode(in); got != "abcd-efgh" {
+			t.Errorf("NormalizeRecoveryCode(%q) = %q", in, got)
+		}
+	}
+}
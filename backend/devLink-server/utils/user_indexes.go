@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	"github.com/ayushmehta03/devLink-backend/database"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// EnsureEmailIndexes makes an email address belong to at most one account.
+// Signup and email change check first, but only the index is race free, so
+// the server must not start without it. Creating it fails while existing
+// accounts share an address.
+func EnsureEmailIndexes(ctx context.Context, client *mongo.Client) error {
+	_, err := database.OpenCollection("users", client).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "email", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+var duplicateIndexName = regexp.MustCompile(`index: (\w+?)_-?1\b`)
+
+// DuplicateKeyField returns the first field of the unique index a duplicate
+// key error was raised by, such as "email" or "handle". It returns "" when
+// err is not a duplicate key error or names no index.
+func DuplicateKeyField(err error) string {
+	var writeErr mongo.WriteException
+	if !errors.As(err, &writeErr) {
+		return ""
+	}
+
+	for _, we := range writeErr.WriteErrors {
+		if we.Code != 11000 {
+			continue
+		}
+		if pattern, ok := we.Raw.Lookup("keyPattern").DocumentOK(); ok {
+			if elems, err := pattern.Elements(); err == nil && len(elems) > 0 {
+				return elems[0].Key()
+			}
+		}
+		// Servers that predate keyPattern only name the index.
+		if m := duplicateIndexName.FindStringSubmatch(we.Message); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
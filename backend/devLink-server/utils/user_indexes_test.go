@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func duplicateKeyError(t *testing.T, message string, raw bson.M) error {
+	t.Helper()
+
+	data, err := bson.Marshal(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000, Message: message, Raw: data}}}
+}
+
+func TestDuplicateKeyField(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			"key pattern",
+			duplicateKeyError(t, "E11000 duplicate key error", bson.M{"keyPattern": bson.M{"email": 1}}),
+			"email",
+		},
+		{
+			"index name only",
+			duplicateKeyError(t, "E11000 duplicate key error collection: devlink.users index: handle_1 dup key: { handle: \"dev\" }", bson.M{}),
+			"handle",
+		},
+		{
+			"other write error",
+			mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 121, Message: "Document failed validation"}}},
+			"",
+		},
+		{"not a write error", errors.New("connection reset"), ""},
+		{"nil", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DuplicateKeyField(tt.err); got != tt.want {
+				t.Errorf("DuplicateKeyField = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -114,7 +114,7 @@ export default function ChatsPage() {
 
     apiFetch("/chat/requests")
       .then(async (res) => {
-        const raw: RawRequest[] = Array.isArray(res) ? res : [];
+        const raw: RawRequest[] = res?.requests || [];
 
         const hydrated = await Promise.all(
           raw.map(async (r) => {
@@ -119,7 +119,7 @@ export default function DashboardPage() {
     const t = setTimeout(async () => {
       try {
         const [pRes, uRes] = await Promise.all([
-          apiFetch(`/posts/tags?t=${search}`),
+          apiFetch(`/posts/search?q=${encodeURIComponent(search)}&limit=2`),
           apiFetch(`/search/users?q=${search}`),
         ]);
 
@@ -1,12 +1,25 @@
 "use client";
 
-import { useState } from "react";
+import { useEffect, useState } from "react";
 import { useRouter } from "next/navigation";
 import toast from "react-hot-toast";
 import { apiFetch } from "@/lib/api";
-import { FaBlog } from "react-icons/fa";
+import { FaBlog, FaGithub } from "react-icons/fa";
 import Link from "next/link";
 
+// Errors the GitHub and OpenID Connect callbacks send back as ?error=.
+const LOGIN_ERRORS: Record<string, string> = {
+  oauth_state: "That sign-in attempt expired. Please try again.",
+  oauth_denied: "Sign-in was cancelled.",
+  oauth_failed: "Sign-in failed. Please try again.",
+  oauth_no_email: "Your provider did not share an email address with us.",
+  oauth_account_exists:
+    "An account with this email already exists. Log in with your password instead.",
+  account_suspended: "Your account is suspended.",
+  account_banned: "Your account is banned.",
+  account_deleted: "This account has been deleted.",
+};
+
 export default function Page() {
   const router = useRouter();
 
@@ -14,18 +27,54 @@ export default function Page() {
   const [password, setPassword] = useState("");
   const [loading, setLoading] = useState(false);
 
+  // Second step for accounts with two-factor authentication. Password,
+  // GitHub and magic link logins all hand over an mfa_token.
+  const [mfaToken, setMfaToken] = useState("");
+  const [mfaCode, setMfaCode] = useState("");
+  const [useRecoveryCode, setUseRecoveryCode] = useState(false);
+  const [redirectTo, setRedirectTo] = useState("/dashboard");
+
+  useEffect(() => {
+    const params = new URLSearchParams(window.location.search);
+
+    const redirect = params.get("redirect") || "";
+    if (redirect.startsWith("/") && !redirect.startsWith("//")) {
+      setRedirectTo(redirect);
+    }
+
+    // The token is kept in state only, so it does not linger in the
+    // address bar or history.
+    const token = params.get("mfa_token");
+    if (token) {
+      setMfaToken(token);
+      params.delete("mfa_token");
+      const query = params.toString();
+      window.history.replaceState(null, "", query ? `/login?${query}` : "/login");
+    }
+
+    const error = params.get("error");
+    if (error) {
+      toast.error(LOGIN_ERRORS[error] || "Sign-in failed. Please try again.");
+    }
+  }, []);
+
   const handleLogin = async (e: React.FormEvent) => {
     e.preventDefault();
     setLoading(true);
 
     try {
-      await apiFetch("/auth/login", {
+      const res = await apiFetch("/auth/login", {
         method: "POST",
         body: JSON.stringify({ email, password }),
       });
 
+      if (res?.mfa_required) {
+        setMfaToken(res.mfa_token);
+        return;
+      }
+
       toast.success("Welcome back!");
-    window.location.href = "/dashboard";
+    window.location.href = redirectTo;
     } catch (err: any) {
       console.log("LOGIN ERROR 👉", err);
 
@@ -63,6 +112,57 @@ export default function Page() {
     }
   };
 
+  const handleVerifyMfa = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setLoading(true);
+
+    try {
+      await apiFetch("/auth/2fa/verify", {
+        method: "POST",
+        body: JSON.stringify(
+          useRecoveryCode
+            ? { mfa_token: mfaToken, recovery_code: mfaCode }
+            : { mfa_token: mfaToken, code: mfaCode }
+        ),
+      });
+
+      toast.success("Welcome back!");
+      window.location.href = redirectTo;
+    } catch (err: any) {
+      const errorMessage = err?.error || "Verification failed";
+
+      // The token lasts a few minutes; once it is gone the login starts
+      // over.
+      if (errorMessage.toLowerCase().includes("mfa token")) {
+        toast.error("Your login timed out. Please log in again.");
+        setMfaToken("");
+        setMfaCode("");
+        return;
+      }
+
+      toast.error(errorMessage);
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const handleMagicLink = async () => {
+    if (!email) {
+      toast.error("Enter your email first");
+      return;
+    }
+
+    try {
+      await apiFetch("/auth/magic/request", {
+        method: "POST",
+        body: JSON.stringify({ email }),
+      });
+      toast.success("Check your inbox for a login link");
+    } catch (err: any) {
+      toast.error(err?.error || "Could not send login link");
+    }
+  };
+
   return (
     <main className="min-h-screen px-4 bg-[var(--color-background-dark)] flex flex-col items-center">
       <div className="w-full max-w-md py-16">
@@ -93,6 +193,48 @@ export default function Page() {
             </p>
           </div>
 
+          {mfaToken ? (
+            <form onSubmit={handleVerifyMfa} className="space-y-4">
+              <div>
+                <label className="block text-sm font-medium text-slate-700 dark:text-slate-300 mb-1">
+                  {useRecoveryCode ? "Recovery code" : "Authentication code"}
+                </label>
+                <input
+                  type="text"
+                  required
+                  autoFocus
+                  autoComplete="one-time-code"
+                  inputMode={useRecoveryCode ? "text" : "numeric"}
+                  value={mfaCode}
+                  onChange={(e) => setMfaCode(e.target.value)}
+                  placeholder={useRecoveryCode ? "xxxx-xxxx" : "123456"}
+                  className="w-full h-12 rounded-lg border border-slate-300 dark:border-slate-700 bg-white dark:bg-[#192633] px-4 text-slate-900 dark:text-white placeholder:text-slate-400 focus:outline-none focus:ring-2 focus:ring-primary/50"
+                />
+              </div>
+
+              <button
+                type="submit"
+                disabled={loading}
+                className="w-full h-12 bg-blue-600 text-white font-semibold rounded-lg shadow-md shadow-primary/20 hover:opacity-95 active:scale-[0.98] transition disabled:opacity-60"
+              >
+                {loading ? "Verifying…" : "Verify"}
+              </button>
+
+              <button
+                type="button"
+                onClick={() => {
+                  setUseRecoveryCode(!useRecoveryCode);
+                  setMfaCode("");
+                }}
+                className="w-full text-primary text-sm font-semibold hover:underline"
+              >
+                {useRecoveryCode
+                  ? "Use your authenticator app instead"
+                  : "Use a recovery code instead"}
+              </button>
+            </form>
+          ) : (
+          <>
           <form onSubmit={handleLogin} className="space-y-4">
             <div>
               <label className="block text-sm font-medium text-slate-700 dark:text-slate-300 mb-1">
@@ -139,6 +281,30 @@ export default function Page() {
             </button>
           </form>
 
+          <div className="flex items-center gap-3 my-6">
+            <div className="flex-1 h-px bg-slate-200 dark:bg-slate-700" />
+            <span className="text-xs text-slate-400">or</span>
+            <div className="flex-1 h-px bg-slate-200 dark:bg-slate-700" />
+          </div>
+
+          <a
+            href={`${process.env.NEXT_PUBLIC_API_URL}/auth/oauth/github?redirect=/dashboard`}
+            className="w-full h-12 flex items-center justify-center gap-2 rounded-lg border border-slate-300 dark:border-slate-700 text-slate-900 dark:text-white font-semibold hover:bg-slate-50 dark:hover:bg-[#192633] transition"
+          >
+            <FaGithub size={20} />
+            Continue with GitHub
+          </a>
+
+          <button
+            type="button"
+            onClick={handleMagicLink}
+            className="w-full h-12 mt-3 rounded-lg border border-slate-300 dark:border-slate-700 text-slate-900 dark:text-white font-semibold hover:bg-slate-50 dark:hover:bg-[#192633] transition"
+          >
+            Email me a login link
+          </button>
+          </>
+          )}
+
           {/* Footer */}
           <p className="text-center text-sm text-slate-500 dark:text-slate-400 mt-6">
             Don&apos;t have an account?{" "}
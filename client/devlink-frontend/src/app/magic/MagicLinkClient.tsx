@@ -0,0 +1,72 @@
+"use client";
+
+import { useEffect, useRef, useState } from "react";
+import { useSearchParams } from "next/navigation";
+import Link from "next/link";
+import { apiFetch } from "@/lib/api";
+
+export default function MagicLinkClient() {
+  const searchParams = useSearchParams();
+  const token = searchParams.get("token") || "";
+
+  const startedRef = useRef(false);
+  const [error, setError] = useState("");
+
+  useEffect(() => {
+    if (startedRef.current) return;
+    startedRef.current = true;
+
+    if (!token) {
+      setError("This login link is incomplete.");
+      return;
+    }
+
+    apiFetch("/auth/magic", {
+      method: "POST",
+      body: JSON.stringify({ token }),
+    })
+      .then((res) => {
+        // Replace rather than push: the link is single-use, so going back
+        // to it could only fail.
+        if (res?.mfa_required) {
+          window.location.replace(
+            `/login?mfa_token=${encodeURIComponent(res.mfa_token)}`
+          );
+          return;
+        }
+        window.location.replace("/dashboard");
+      })
+      .catch((err: any) => {
+        setError(
+          err?.error ||
+            "This login link is invalid, expired, or was opened in a different browser."
+        );
+      });
+  }, [token]);
+
+  return (
+    <main className="min-h-screen px-4 bg-[var(--color-background-dark)] flex flex-col items-center">
+      <div className="w-full max-w-md py-16">
+        <div className="bg-white dark:bg-[#121c26] rounded-2xl p-6 sm:p-8 shadow-lg text-center">
+          {error ? (
+            <>
+              <p className="text-slate-900 dark:text-white font-semibold mb-4">
+                {error}
+              </p>
+              <Link
+                href="/login"
+                className="text-primary font-semibold hover:underline"
+              >
+                Back to login
+              </Link>
+            </>
+          ) : (
+            <p className="text-slate-500 dark:text-slate-400">
+              Logging you in…
+            </p>
+          )}
+        </div>
+      </div>
+    </main>
+  );
+}
@@ -0,0 +1,10 @@
+import { Suspense } from "react";
+import MagicLinkClient from "./MagicLinkClient";
+
+export default function MagicLinkPage() {
+  return (
+    <Suspense fallback={<div className="text-white p-6">Logging you in…</div>}>
+      <MagicLinkClient />
+    </Suspense>
+  );
+}
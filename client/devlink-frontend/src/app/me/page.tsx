@@ -51,6 +51,8 @@ type Post = {
   slug: string;
   view_count: number;
   published: boolean;
+  status: "draft" | "scheduled" | "published" | "archived";
+  publish_at?: string;
 };
 
 export default function MyProfilePage() {
@@ -101,7 +103,6 @@ export default function MyProfilePage() {
         title: data.title,
         content: data.content,
         image_url: data.image_url,
-        published: editing.published,
         tags: [],
       }),
     });
@@ -112,7 +113,7 @@ export default function MyProfilePage() {
   const archivePost = async (post: Post) => {
     await apiFetch(`/updatepost/${post.id}`, {
       method: "PUT",
-      body: JSON.stringify({ ...post, published: false }),
+      body: JSON.stringify({ status: "archived" }),
     });
     refresh();
   };
@@ -120,7 +121,7 @@ export default function MyProfilePage() {
   const publishPost = async (post: Post) => {
     await apiFetch(`/updatepost/${post.id}`, {
       method: "PUT",
-      body: JSON.stringify({ ...post, published: true }),
+      body: JSON.stringify({ status: "published" }),
     });
     refresh();
   };
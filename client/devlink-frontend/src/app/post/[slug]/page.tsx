@@ -20,9 +20,13 @@ type Post = {
   title: string;
   slug: string;
   content: string;
+  content_html?: string;
+  toc?: { level: number; id: string; text: string }[];
+  reading_minutes?: number;
   tags?: string[];
   image_url?: string;
   created_at: string;
+  published_at?: string;
   view_count: number;
   author: Author;
 };
@@ -177,7 +181,11 @@ export default function PostPage() {
                     @{post.author.username}
                   </p>
                   <p className="text-slate-400 text-xs">
-                    {formatDate(post.created_at)} • {getReadTime(post.content)} •{" "}
+                    {formatDate(post.published_at ?? post.created_at)} •{" "}
+                    {post.reading_minutes
+                      ? `${post.reading_minutes} min read`
+                      : getReadTime(post.content)}{" "}
+                    •{" "}
                     {post.view_count} views
                   </p>
                 </div>
@@ -197,9 +205,32 @@ export default function PostPage() {
                 </div>
               )}
 
-              <div className="text-slate-300 leading-relaxed space-y-4 whitespace-pre-line">
-                {post.content}
-              </div>
+              {post.toc && post.toc.length > 1 && (
+                <nav className="mb-10 text-sm text-slate-400 space-y-1">
+                  {post.toc.map((h) => (
+                    <a
+                      key={h.id}
+                      href={`#${h.id}`}
+                      className="block hover:text-blue-400"
+                      style={{ paddingLeft: `${(h.level - 1) * 12}px` }}
+                    >
+                      {h.text}
+                    </a>
+                  ))}
+                </nav>
+              )}
+
+              {post.content_html ? (
+                // Rendered and sanitized by the server.
+                <div
+                  className="post-body text-slate-300 leading-relaxed space-y-4"
+                  dangerouslySetInnerHTML={{ __html: post.content_html }}
+                />
+              ) : (
+                <div className="text-slate-300 leading-relaxed space-y-4 whitespace-pre-line">
+                  {post.content}
+                </div>
+              )}
 
               {suggested.length > 0 && (
                 <section className="mt-24 pt-10 border-t border-white/5">
@@ -119,6 +119,7 @@ export default function Page() {
                 rows={3}
                 value={bio}
                 onChange={(e) => setBio(e.target.value)}
+                maxLength={160}
                 placeholder="Backend dev | Go | Open source"
                 className="w-full rounded-lg border border-slate-300 dark:border-slate-700 bg-white dark:bg-[#192633] px-4 py-2 text-slate-900 dark:text-white placeholder:text-slate-400 focus:outline-none focus:ring-2 focus:ring-primary/50 resize-none"
               />
@@ -71,7 +71,7 @@ export default function SearchClient() {
     const timer = setTimeout(async () => {
       try {
         if (tab === "posts") {
-          const res = await apiFetch(`/posts/tags?t=${query}`);
+          const res = await apiFetch(`/posts/search?q=${encodeURIComponent(query)}`);
           setPosts(res.posts || []);
         } else {
           const res = await apiFetch(`/search/users?q=${query}`);
@@ -22,6 +22,7 @@ type Post = {
   content: string;
   image_url?: string;
   created_at: string;
+  published_at?: string;
 };
 
 type Stats = {
@@ -206,7 +207,7 @@ export default function UserProfilePage() {
                 </p>
 
                 <div className="flex gap-2 text-xs text-slate-400 mt-3">
-                  <span>{formatDate(post.created_at)}</span>
+                  <span>{formatDate(post.published_at ?? post.created_at)}</span>
                   <span>•</span>
                   <span>{readTime(post.content)}</span>
                 </div>
@@ -1,9 +1,27 @@
 const API_URL = process.env.NEXT_PUBLIC_API_URL!;
 
+let refreshing: Promise<boolean> | null = null;
+
+const refreshSession = () => {
+  if (!refreshing) {
+    refreshing = fetch(`${API_URL}/auth/refresh`, {
+      method: "POST",
+      credentials: "include",
+    })
+      .then((res) => res.ok)
+      .catch(() => false)
+      .finally(() => {
+        refreshing = null;
+      });
+  }
+  return refreshing;
+};
+
 export const apiFetch = async (
   url: string,
-  options: RequestInit = {}
-) => {
+  options: RequestInit = {},
+  retry = true
+): Promise<any> => {
   const res = await fetch(`${API_URL}${url}`, {
     credentials: "include",
     ...options,
@@ -13,6 +31,12 @@ export const apiFetch = async (
     },
   });
 
+  if (res.status === 401 && retry && !url.startsWith("/auth/refresh")) {
+    if (await refreshSession()) {
+      return apiFetch(url, options, false);
+    }
+  }
+
   if (!res.ok) {
     let error: any = { status: res.status };
     try {